@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,6 +21,7 @@ var Commands = [...]string{"sync-channel", "create-show", "sync", "add-video", "
 func main() {
 	godotenv.Load()
 	rss.Init()
+	rss.ScheduleSync()
 
 	application := tview.NewApplication().SetTitle("TubeCast")
 
@@ -32,7 +35,12 @@ func main() {
 		AddItem("create a show", "Create a new show", 'c', nil).
 		AddItem("subscribe", "Get latest videos from a YT channel easily", 's', nil).
 		AddItem("sync", "Add latest episodes to all shows from your subscribed channels", 'a', nil).
+		AddItem("backfill", "Import older videos of a subscribed channel", 'f', nil).
+		AddItem("mirror a podcast", "Copy the episodes of another podcast's RSS feed into a show", 'm', nil).
 		AddItem("add episodes", "Add episodes to a show", 'i', nil).
+		AddItem("add a file", "Add a local audio or video file to a show", 'l', nil).
+		AddItem("export shows", "Write an OPML file to follow every show at once", 'x', nil).
+		AddItem("import shows", "Create shows and subscriptions from an OPML file", 'o', nil).
 		AddItem("delete a show", "Remove a show", 'd', nil).
 		AddItem("set env", "Set environment variables", 'e', nil).
 		AddItem("quit", "Exit the app", 'q', nil)
@@ -57,8 +65,18 @@ func main() {
 			pages.AddAndSwitchToPage("sync-channel", SubscribeForm(application, pages), true)
 		case "sync":
 			Sync(application, pages)
+		case "backfill":
+			pages.AddAndSwitchToPage("backfill", BackfillForm(application, pages), true)
+		case "mirror a podcast":
+			pages.AddAndSwitchToPage("mirror-podcast", MirrorPodcastForm(application, pages), true)
 		case "add episodes":
 			pages.AddAndSwitchToPage("add-videos", AddEpisodesForm(application, pages), true)
+		case "add a file":
+			pages.AddAndSwitchToPage("add-file", AddFileForm(application, pages), true)
+		case "export shows":
+			ExportShows(application, pages)
+		case "import shows":
+			pages.AddAndSwitchToPage("import-shows", ImportShowsForm(application, pages), true)
 		case "delete a show":
 			pages.AddAndSwitchToPage("remove-show", RemoveShow(application, pages), true)
 		case "set env":
@@ -128,16 +146,41 @@ func ShowSpinnerModal(app *tview.Application, pages *tview.Pages, label string)
 func Sync(app *tview.Application, pages *tview.Pages) {
 	stop := ShowSpinnerModal(app, pages, "Syncing Shows...")
 	go func() {
-		err := rss.Sync()
+		report := rss.Sync()
 		stop()
 		app.QueueUpdateDraw(func() {
 			var modal *tview.Modal
-			if err != nil {
-				modal = ShowModal(fmt.Sprintf("Syncing failed: %v", err), []string{"OK"}, func(_ int, _ string) {
+			if report.HasErrors() {
+				modal = ShowModal(fmt.Sprintf("Synced with errors:\n%v", report), []string{"OK"}, func(_ int, _ string) {
+					pages.RemovePage("modal")
+				})
+			} else {
+				modal = ShowModal(fmt.Sprintf("Successfully synced\n%v", report), []string{"OK"}, func(_ int, _ string) {
+					pages.RemovePage("modal")
+				})
+			}
+			pages.AddPage("modal", modal, true, true)
+		})
+	}()
+}
+
+func ExportShows(app *tview.Application, pages *tview.Pages) {
+	stop := ShowSpinnerModal(app, pages, "Exporting Shows...")
+	go func() {
+		result, err := rss.ExportShows()
+		stop()
+		app.QueueUpdateDraw(func() {
+			var modal *tview.Modal
+			if err == nil {
+				modal = ShowModal(fmt.Sprintf("Shows exported.\nOPML link: %s", result), []string{"OK", "COPY OPML LINK"}, func(_ int, label string) {
+					switch label {
+					case "COPY OPML LINK":
+						clipboard.WriteAll(result)
+					}
 					pages.RemovePage("modal")
 				})
 			} else {
-				modal = ShowModal("Successfully synced", []string{"OK"}, func(_ int, _ string) {
+				modal = ShowModal(fmt.Sprintf("%v", err), []string{"OK"}, func(_ int, _ string) {
 					pages.RemovePage("modal")
 				})
 			}
@@ -146,6 +189,82 @@ func Sync(app *tview.Application, pages *tview.Pages) {
 	}()
 }
 
+func ImportShowsForm(app *tview.Application, pages *tview.Pages) tview.Primitive {
+	fileIF := tview.NewInputField().
+		SetLabel("OPML File:  ").
+		SetFieldWidth(60).
+		SetPlaceholder("path inside tubecast/files or absolute path")
+	form := tview.NewForm()
+	form.SetTitle(" Import Shows ")
+	form.
+		AddFormItem(fileIF).
+		AddButton("Import", func() {
+			file := fileIF.GetText()
+			if file == "" {
+				modal := ShowModal("OPML File is required", []string{"Try Again"}, func(_ int, _ string) {
+					pages.RemovePage("modal")
+				})
+				pages.AddPage("modal", modal, true, true)
+				return
+			}
+			stop := ShowSpinnerModal(app, pages, "Importing shows...")
+			go func() {
+				report, err := rss.ImportShows(file)
+				stop()
+				app.QueueUpdateDraw(func() {
+					var modal *tview.Modal
+					if err == nil {
+						modal = ShowModal(fmt.Sprintf("%v\nRun sync to add their episodes.", report), []string{"OK"}, func(_ int, _ string) {
+							pages.
+								SwitchToPage("menu").
+								RemovePage("modal")
+						})
+					} else {
+						modal = ShowModal(fmt.Sprintf("%v", err), []string{"OK"}, func(_ int, _ string) {
+							pages.RemovePage("modal")
+						})
+					}
+					pages.AddPage("modal", modal, true, true)
+				})
+			}()
+		}).
+		AddButton("cancel", func() {
+			pages.SwitchToPage("menu")
+		}).
+		SetFocus(0)
+	currentIdx := 0
+	totalItem := form.GetFormItemCount() + form.GetButtonCount()
+	focusItem := func(idx int) {
+		if idx < 0 {
+			idx = totalItem - 1
+		} else if idx >= totalItem {
+			idx = 0
+		}
+		currentIdx = idx
+		if idx >= form.GetFormItemCount() {
+			app.SetFocus(form.GetButton(idx - form.GetFormItemCount()))
+		} else {
+			app.SetFocus(form.GetFormItem(idx))
+		}
+	}
+
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyUp:
+			focusItem(currentIdx - 1)
+			return nil
+		case tcell.KeyDown:
+			focusItem(currentIdx + 1)
+			return nil
+		case tcell.KeyEsc:
+			pages.SwitchToPage("menu")
+			return nil
+		}
+		return event
+	})
+	return form
+}
+
 func ShowModal(message string, buttonLabels []string, cb func(int, string)) *tview.Modal {
 	modal := tview.NewModal().
 		SetText(message).
@@ -297,6 +416,106 @@ func RemoveShow(app *tview.Application, pages *tview.Pages) tview.Primitive {
 	return shows
 }
 
+func AddFileForm(app *tview.Application, pages *tview.Pages) tview.Primitive {
+	titleIF := tview.NewInputField().
+		SetLabel("Show Title:           ").
+		SetFieldWidth(30)
+	fileIF := tview.NewInputField().
+		SetLabel("Audio or Video File:  ").
+		SetFieldWidth(60).
+		SetPlaceholder("path inside tubecast/files or absolute path")
+	episodeIF := tview.NewInputField().
+		SetLabel("Episode Title:        ").
+		SetFieldWidth(60)
+	descriptionIF := tview.NewInputField().
+		SetLabel("Description:          ").
+		SetFieldWidth(80)
+	artworkIF := tview.NewInputField().
+		SetLabel("Artwork (optional):   ").
+		SetFieldWidth(60).
+		SetPlaceholder("jpg, png or webp; the show cover if empty")
+
+	form := tview.NewForm()
+	form.SetTitle(" Add a File ")
+	form.
+		AddFormItem(titleIF).
+		AddFormItem(fileIF).
+		AddFormItem(episodeIF).
+		AddFormItem(descriptionIF).
+		AddFormItem(artworkIF).
+		AddButton("Add", func() {
+			title := titleIF.GetText()
+			file := fileIF.GetText()
+			episode := episodeIF.GetText()
+			if title == "" || file == "" || episode == "" {
+				modal := ShowModal("Show title, file and episode title are required", []string{"Try Again"}, func(_ int, _ string) {
+					pages.RemovePage("modal")
+				})
+				pages.AddPage("modal", modal, true, true)
+			} else {
+				stop := ShowSpinnerModal(app, pages, "Adding File...")
+				go func() {
+					link, err := rss.AddFileToShow(title, file, episode, descriptionIF.GetText(), artworkIF.GetText())
+					stop()
+					app.QueueUpdateDraw(func() {
+						var modal *tview.Modal
+						if err == nil {
+							modal = ShowModal(fmt.Sprintf("The file has been added successfully.\nShow link: %s", link), []string{"OK", "COPY SHOW LINK"}, func(_ int, label string) {
+								switch label {
+								case "COPY SHOW LINK":
+									clipboard.WriteAll(link)
+								}
+								pages.
+									SwitchToPage("menu").
+									RemovePage("modal")
+							})
+						} else {
+							modal = ShowModal(fmt.Sprintf("The file could not be added: %v", err), []string{"OK"}, func(_ int, _ string) {
+								pages.RemovePage("modal")
+							})
+						}
+						pages.AddPage("modal", modal, true, true)
+					})
+				}()
+			}
+		}).
+		AddButton("cancel", func() {
+			pages.SwitchToPage("menu")
+		}).
+		SetFocus(0)
+	currentIdx := 0
+	totalItem := form.GetFormItemCount() + form.GetButtonCount()
+	focusItem := func(idx int) {
+		if idx < 0 {
+			idx = totalItem - 1
+		} else if idx >= totalItem {
+			idx = 0
+		}
+		currentIdx = idx
+		if idx >= form.GetFormItemCount() {
+			app.SetFocus(form.GetButton(idx - form.GetFormItemCount()))
+		} else {
+			app.SetFocus(form.GetFormItem(idx))
+		}
+	}
+
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyUp:
+			focusItem(currentIdx - 1)
+			return nil
+		case tcell.KeyDown:
+			focusItem(currentIdx + 1)
+			return nil
+		case tcell.KeyEsc:
+			pages.SwitchToPage("menu")
+			return nil
+		}
+		return event
+	})
+	return form
+}
+
 func AddEpisodesForm(app *tview.Application, pages *tview.Pages) tview.Primitive {
 	titleIF := tview.NewInputField().
 		SetLabel("Show Title:                    ").
@@ -397,30 +616,183 @@ func AddEpisodesForm(app *tview.Application, pages *tview.Pages) tview.Primitive
 	return form
 }
 
+func MirrorPodcastForm(app *tview.Application, pages *tview.Pages) tview.Primitive {
+	titleIF := tview.NewInputField().
+		SetLabel("Show Title:           ").
+		SetFieldWidth(40)
+	feedIF := tview.NewInputField().
+		SetLabel("Podcast RSS Feed URL: ").
+		SetFieldWidth(60)
+	minDurationIF := tview.NewInputField().
+		SetLabel("Min Minutes:          ").
+		SetFieldWidth(6).
+		SetAcceptanceFunc(tview.InputFieldInteger)
+	maxDurationIF := tview.NewInputField().
+		SetLabel("Max Minutes:          ").
+		SetFieldWidth(6).
+		SetAcceptanceFunc(tview.InputFieldInteger)
+	includeIF := tview.NewInputField().
+		SetLabel("Include Regex:        ").
+		SetFieldWidth(40)
+	excludeIF := tview.NewInputField().
+		SetLabel("Exclude Regex:        ").
+		SetFieldWidth(40)
+	allCB := tview.NewCheckbox().
+		SetLabel("Mirror All Episodes:  ")
+	form := tview.NewForm()
+	form.SetTitle(" Mirror a Podcast ")
+	form.
+		AddFormItem(titleIF).
+		AddFormItem(feedIF).
+		AddFormItem(minDurationIF).
+		AddFormItem(maxDurationIF).
+		AddFormItem(includeIF).
+		AddFormItem(excludeIF).
+		AddFormItem(allCB).
+		AddButton("Mirror", func() {
+			title := titleIF.GetText()
+			feedUrl := feedIF.GetText()
+			minMinutes, _ := strconv.Atoi(minDurationIF.GetText())
+			maxMinutes, _ := strconv.Atoi(maxDurationIF.GetText())
+			filter := rss.SubscriptionFilter{
+				MinDuration:    uint32(minMinutes * 60),
+				MaxDuration:    uint32(maxMinutes * 60),
+				IncludePattern: includeIF.GetText(),
+				ExcludePattern: excludeIF.GetText(),
+			}
+			if title == "" || feedUrl == "" {
+				modal := ShowModal("Title and Podcast RSS Feed URL are required", []string{"Try Again"}, func(_ int, _ string) {
+					pages.RemovePage("modal")
+				})
+				pages.AddPage("modal", modal, true, true)
+				return
+			}
+			stop := ShowSpinnerModal(app, pages, "Mirroring episodes...")
+			go func() {
+				result, err := rss.SyncPodcast(title, feedUrl, filter, allCB.IsChecked())
+				stop()
+				app.QueueUpdateDraw(func() {
+					var modal *tview.Modal
+					if err == nil {
+						modal = ShowModal(fmt.Sprintf("Podcast mirrored successfully.\nShow link: %s", result), []string{"OK", "COPY SHOW LINK"}, func(_ int, label string) {
+							switch label {
+							case "COPY SHOW LINK":
+								clipboard.WriteAll(result)
+							}
+							pages.
+								SwitchToPage("menu").
+								RemovePage("modal")
+						})
+					} else {
+						modal = ShowModal(fmt.Sprintf("%v", err), []string{"OK"}, func(_ int, _ string) {
+							pages.RemovePage("modal")
+						})
+					}
+					pages.AddPage("modal", modal, true, true)
+				})
+			}()
+		}).
+		AddButton("cancel", func() {
+			pages.SwitchToPage("menu")
+		}).
+		SetFocus(0)
+	currentIdx := 0
+	totalItem := form.GetFormItemCount() + form.GetButtonCount()
+	focusItem := func(idx int) {
+		if idx < 0 {
+			idx = totalItem - 1
+		} else if idx >= totalItem {
+			idx = 0
+		}
+		currentIdx = idx
+		if idx >= form.GetFormItemCount() {
+			app.SetFocus(form.GetButton(idx - form.GetFormItemCount()))
+		} else {
+			app.SetFocus(form.GetFormItem(idx))
+		}
+	}
+
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyUp:
+			focusItem(currentIdx - 1)
+			return nil
+		case tcell.KeyDown:
+			focusItem(currentIdx + 1)
+			return nil
+		case tcell.KeyEsc:
+			pages.SwitchToPage("menu")
+			return nil
+		}
+		return event
+	})
+	return form
+}
+
 func SubscribeForm(app *tview.Application, pages *tview.Pages) tview.Primitive {
 	titleIF := tview.NewInputField().
-		SetLabel("Show Title:    ").
+		SetLabel("Show Title:           ").
 		SetFieldWidth(40)
 	channelIdIF := tview.NewInputField().
-		SetLabel("Channel ID:    ").
-		SetFieldWidth(20)
+		SetLabel("Channel or Playlist:  ").
+		SetFieldWidth(60)
+	minDurationIF := tview.NewInputField().
+		SetLabel("Min Minutes:          ").
+		SetFieldWidth(6).
+		SetAcceptanceFunc(tview.InputFieldInteger)
+	maxDurationIF := tview.NewInputField().
+		SetLabel("Max Minutes:          ").
+		SetFieldWidth(6).
+		SetAcceptanceFunc(tview.InputFieldInteger)
+	includeIF := tview.NewInputField().
+		SetLabel("Include Regex:        ").
+		SetFieldWidth(40)
+	excludeIF := tview.NewInputField().
+		SetLabel("Exclude Regex:        ").
+		SetFieldWidth(40)
+	shortsCB := tview.NewCheckbox().
+		SetLabel("Skip Shorts:          ").
+		SetChecked(true)
+	liveCB := tview.NewCheckbox().
+		SetLabel("Skip Livestreams:     ")
+	membersCB := tview.NewCheckbox().
+		SetLabel("Skip Members Only:    ").
+		SetChecked(true)
 	form := tview.NewForm()
 	form.SetTitle(" Add an Episode ")
 	form.
 		AddFormItem(titleIF).
 		AddFormItem(channelIdIF).
+		AddFormItem(minDurationIF).
+		AddFormItem(maxDurationIF).
+		AddFormItem(includeIF).
+		AddFormItem(excludeIF).
+		AddFormItem(shortsCB).
+		AddFormItem(liveCB).
+		AddFormItem(membersCB).
 		AddButton("Add", func() {
 			title := titleIF.GetText()
 			channelId := channelIdIF.GetText()
+			minMinutes, _ := strconv.Atoi(minDurationIF.GetText())
+			maxMinutes, _ := strconv.Atoi(maxDurationIF.GetText())
+			filter := rss.SubscriptionFilter{
+				MinDuration:     uint32(minMinutes * 60),
+				MaxDuration:     uint32(maxMinutes * 60),
+				IncludePattern:  includeIF.GetText(),
+				ExcludePattern:  excludeIF.GetText(),
+				SkipShorts:      shortsCB.IsChecked(),
+				SkipLive:        liveCB.IsChecked(),
+				SkipMembersOnly: membersCB.IsChecked(),
+			}
 			if title == "" || channelId == "" {
-				modal := ShowModal("Title and Channel ID are required", []string{"Try Again"}, func(_ int, _ string) {
+				modal := ShowModal("Title and Channel or Playlist are required", []string{"Try Again"}, func(_ int, _ string) {
 					pages.RemovePage("modal")
 				})
 				pages.AddPage("modal", modal, true, true)
 			}
-			stop := ShowSpinnerModal(app, pages, "Subcribing to the channel...")
+			stop := ShowSpinnerModal(app, pages, "Subscribing...")
 			go func() {
-				result, err := rss.SyncChannel(title, channelId)
+				result, err := rss.SyncChannel(title, channelId, filter)
 				stop()
 				app.QueueUpdateDraw(func() {
 
@@ -487,6 +859,93 @@ func SubscribeForm(app *tview.Application, pages *tview.Pages) tview.Primitive {
 	return form
 }
 
+func BackfillForm(app *tview.Application, pages *tview.Pages) tview.Primitive {
+	titleIF := tview.NewInputField().
+		SetLabel("Show Title:           ").
+		SetFieldWidth(40)
+	channelIF := tview.NewInputField().
+		SetLabel("Channel or Playlist:  ").
+		SetFieldWidth(60)
+	oldestFirstCB := tview.NewCheckbox().
+		SetLabel("Oldest First:         ")
+	form := tview.NewForm()
+	form.SetTitle(" Backfill a Subscription ")
+	form.
+		AddFormItem(titleIF).
+		AddFormItem(channelIF).
+		AddFormItem(oldestFirstCB).
+		AddButton("Start", func() {
+			title := titleIF.GetText()
+			channel := channelIF.GetText()
+			if title == "" || channel == "" {
+				modal := ShowModal("Title and Channel or Playlist are required", []string{"Try Again"}, func(_ int, _ string) {
+					pages.RemovePage("modal")
+				})
+				pages.AddPage("modal", modal, true, true)
+				return
+			}
+			stop := ShowSpinnerModal(app, pages, "Importing older videos...")
+			go func() {
+				result, err := rss.BackfillChannel(title, channel, oldestFirstCB.IsChecked())
+				stop()
+				app.QueueUpdateDraw(func() {
+					var modal *tview.Modal
+					if err == nil {
+						modal = ShowModal(fmt.Sprintf("Backfill finished.\nShow link: %s", result), []string{"OK", "COPY SHOW LINK"}, func(_ int, label string) {
+							switch label {
+							case "COPY SHOW LINK":
+								clipboard.WriteAll(result)
+							}
+							pages.
+								SwitchToPage("menu").
+								RemovePage("modal")
+						})
+					} else {
+						modal = ShowModal(fmt.Sprintf("%v\nRun the backfill again to resume.", err), []string{"OK"}, func(_ int, _ string) {
+							pages.RemovePage("modal")
+						})
+					}
+					pages.AddPage("modal", modal, true, true)
+				})
+			}()
+		}).
+		AddButton("cancel", func() {
+			pages.SwitchToPage("menu")
+		}).
+		SetFocus(0)
+	currentIdx := 0
+	totalItem := form.GetFormItemCount() + form.GetButtonCount()
+	focusItem := func(idx int) {
+		if idx < 0 {
+			idx = totalItem - 1
+		} else if idx >= totalItem {
+			idx = 0
+		}
+		currentIdx = idx
+		if idx >= form.GetFormItemCount() {
+			app.SetFocus(form.GetButton(idx - form.GetFormItemCount()))
+		} else {
+			app.SetFocus(form.GetFormItem(idx))
+		}
+	}
+
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyUp:
+			focusItem(currentIdx - 1)
+			return nil
+		case tcell.KeyDown:
+			focusItem(currentIdx + 1)
+			return nil
+		case tcell.KeyEsc:
+			pages.SwitchToPage("menu")
+			return nil
+		}
+		return event
+	})
+	return form
+}
+
 func CreateShowsPage() *tview.List {
 	shows := tview.NewList()
 	shows.
@@ -516,13 +975,21 @@ func ListEpisodes(app *tview.Application, pages *tview.Pages, showTitle string)
 	episodes := tview.NewList()
 	episodes.SetTitle(fmt.Sprintf("Show %s Episodes", showTitle))
 	episodes.AddItem("← Back", "Return to the Shows", 'b', nil)
+	episodes.AddItem("▶ Removed episodes", "Videos that sync will not add again", 'r', nil)
+	episodes.AddItem("▶ Failed videos", "Videos that could not be added", 'f', nil)
+	episodes.AddItem("▶ Numbering", "Show type and how episodes are numbered", 'n', nil)
+	episodes.AddItem("▶ Validate feed", "Check the feed against Apple Podcasts and Podcast Index rules", 'v', nil)
 	if len(episodeInfos) == 0 {
 		episodes.AddItem(" No Episode Found ", "", 0, nil)
 	} else {
 		episodes.AddItem("▶ Copy link to clipboard", fmt.Sprintf("Paste it on your podcast app:%v", rss.GetFeedUrl(showTitle)), 'c', nil)
+		episodes.AddItem("▶ Copy Atom link", fmt.Sprintf("Paste it on your feed reader:%v", rss.GetAtomFeedUrl(showTitle)), 'a', nil)
+		episodes.AddItem("▶ Copy JSON Feed link", fmt.Sprintf("Paste it on your feed reader:%v", rss.GetJsonFeedUrl(showTitle)), 'j', nil)
 		episodes.AddItem("========= All Episodes =========", "", 0, nil)
 		for _, info := range episodeInfos {
-			episodes.AddItem(info.Title, info.Author, 0, nil)
+			episodes.AddItem(info.Title, GetEpisodeLabel(info), 0, func() {
+				EpisodeForm(app, pages, showTitle, info)
+			})
 		}
 	}
 	episodes.SetSelectedFunc(func(_ int, mainText, secondaryText string, _ rune) {
@@ -535,14 +1002,339 @@ func ListEpisodes(app *tview.Application, pages *tview.Pages, showTitle string)
 			// no-op
 		case "▶ Copy link to clipboard":
 			clipboard.WriteAll(rss.GetFeedUrl(showTitle))
+		case "▶ Copy Atom link":
+			clipboard.WriteAll(rss.GetAtomFeedUrl(showTitle))
+		case "▶ Copy JSON Feed link":
+			clipboard.WriteAll(rss.GetJsonFeedUrl(showTitle))
+		case "▶ Removed episodes":
+			ListExcludedEpisodes(app, pages, showTitle)
+		case "▶ Failed videos":
+			ListFailedVideos(app, pages, showTitle)
+		case "▶ Numbering":
+			pages.AddAndSwitchToPage("numbering", NumberingForm(app, pages, showTitle), true)
+		case "▶ Validate feed":
+			ValidateShow(app, pages, showTitle)
 		default:
-			// episode
-			RemoveEpisode(app, pages, showTitle, mainText, secondaryText)
+			// episodes open their own form
 		}
 	})
 	pages.AddAndSwitchToPage("episodes", episodes, true)
 }
 
+func ValidateShow(app *tview.Application, pages *tview.Pages, showTitle string) {
+	stop := ShowSpinnerModal(app, pages, "Validating feed...")
+	go func() {
+		report, err := rss.ValidateShow(showTitle)
+		stop()
+		app.QueueUpdateDraw(func() {
+			message := fmt.Sprintf("%v", report)
+			if err != nil {
+				message = fmt.Sprintf("%v", err)
+			}
+			modal := ShowModal(message, []string{"OK"}, func(_ int, _ string) {
+				pages.RemovePage("modal")
+			})
+			pages.AddPage("modal", modal, true, true)
+		})
+	}()
+}
+
+// GetEpisodeLabel shows the author, the season and episode numbers and the type of an episode
+func GetEpisodeLabel(info rss.EpisodeInfo) string {
+	label := info.Author
+	if info.Season > 0 {
+		label += fmt.Sprintf("  S%d", info.Season)
+	}
+	if info.Episode > 0 {
+		label += fmt.Sprintf("  E%d", info.Episode)
+	}
+	if info.EpisodeType != rss.EPISODE_FULL {
+		label += "  " + info.EpisodeType
+	}
+	return label
+}
+
+func NumberingForm(app *tview.Application, pages *tview.Pages, showTitle string) tview.Primitive {
+	showType, policy, _ := rss.GetShowNumbering(showTitle)
+	typeDD := tview.NewDropDown().
+		SetLabel("Show Type:  ").
+		SetOptions(rss.SHOW_TYPES, nil).
+		SetCurrentOption(slices.Index(rss.SHOW_TYPES, showType))
+	policies := make([]string, len(rss.NUMBERING_POLICIES))
+	for i, p := range rss.NUMBERING_POLICIES {
+		policies[i] = string(p)
+	}
+	policyDD := tview.NewDropDown().
+		SetLabel("Numbering:  ").
+		SetOptions(policies, nil).
+		SetCurrentOption(slices.Index(policies, string(policy)))
+	form := tview.NewForm()
+	form.SetTitle(fmt.Sprintf(" Show %s Numbering ", showTitle))
+	form.
+		AddFormItem(typeDD).
+		AddFormItem(policyDD).
+		AddButton("Save", func() {
+			_, showType := typeDD.GetCurrentOption()
+			_, policy := policyDD.GetCurrentOption()
+			stop := ShowSpinnerModal(app, pages, "Renumbering episodes...")
+			go func() {
+				_, err := rss.SetShowNumbering(showTitle, showType, rss.NumberingPolicy(policy))
+				stop()
+				app.QueueUpdateDraw(func() {
+					var modal *tview.Modal
+					if err == nil {
+						modal = ShowModal("Numbering saved", []string{"OK"}, func(_ int, _ string) {
+							pages.RemovePage("modal")
+							ListEpisodes(app, pages, showTitle)
+						})
+					} else {
+						modal = ShowModal(fmt.Sprintf("%v", err), []string{"OK"}, func(_ int, _ string) {
+							pages.RemovePage("modal")
+						})
+					}
+					pages.AddPage("modal", modal, true, true)
+				})
+			}()
+		}).
+		AddButton("cancel", func() {
+			pages.SwitchToPage("episodes")
+		}).
+		SetFocus(0)
+	currentIdx := 0
+	totalItem := form.GetFormItemCount() + form.GetButtonCount()
+	focusItem := func(idx int) {
+		if idx < 0 {
+			idx = totalItem - 1
+		} else if idx >= totalItem {
+			idx = 0
+		}
+		currentIdx = idx
+		if idx >= form.GetFormItemCount() {
+			app.SetFocus(form.GetButton(idx - form.GetFormItemCount()))
+		} else {
+			app.SetFocus(form.GetFormItem(idx))
+		}
+	}
+
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if typeDD.IsOpen() || policyDD.IsOpen() {
+			return event
+		}
+		switch event.Key() {
+		case tcell.KeyUp:
+			focusItem(currentIdx - 1)
+			return nil
+		case tcell.KeyDown:
+			focusItem(currentIdx + 1)
+			return nil
+		case tcell.KeyEsc:
+			pages.SwitchToPage("episodes")
+			return nil
+		}
+		return event
+	})
+	return form
+}
+
+// EpisodeForm sets the type and numbers of an episode, or deletes it
+func EpisodeForm(app *tview.Application, pages *tview.Pages, showTitle string, info rss.EpisodeInfo) {
+	typeDD := tview.NewDropDown().
+		SetLabel("Episode Type:  ").
+		SetOptions(rss.EPISODE_TYPES, nil).
+		SetCurrentOption(slices.Index(rss.EPISODE_TYPES, info.EpisodeType))
+	seasonIF := tview.NewInputField().
+		SetLabel("Season:        ").
+		SetFieldWidth(6).
+		SetText(strconv.Itoa(info.Season)).
+		SetAcceptanceFunc(tview.InputFieldInteger)
+	episodeIF := tview.NewInputField().
+		SetLabel("Episode:       ").
+		SetFieldWidth(6).
+		SetText(strconv.Itoa(info.Episode)).
+		SetAcceptanceFunc(tview.InputFieldInteger)
+	form := tview.NewForm()
+	form.SetTitle(fmt.Sprintf(" %s ", info.Title))
+	form.
+		AddFormItem(typeDD).
+		AddFormItem(seasonIF).
+		AddFormItem(episodeIF).
+		AddButton("Save", func() {
+			_, episodeType := typeDD.GetCurrentOption()
+			season, _ := strconv.Atoi(seasonIF.GetText())
+			episode, _ := strconv.Atoi(episodeIF.GetText())
+			stop := ShowSpinnerModal(app, pages, "Saving episode...")
+			go func() {
+				_, err := rss.SetEpisodeNumbering(showTitle, info.GUID, episodeType, season, episode)
+				stop()
+				app.QueueUpdateDraw(func() {
+					var modal *tview.Modal
+					if err == nil {
+						modal = ShowModal("Episode saved.\nShows numbered by publication or year keep their automatic numbers.", []string{"OK"}, func(_ int, _ string) {
+							pages.RemovePage("modal")
+							ListEpisodes(app, pages, showTitle)
+						})
+					} else {
+						modal = ShowModal(fmt.Sprintf("%v", err), []string{"OK"}, func(_ int, _ string) {
+							pages.RemovePage("modal")
+						})
+					}
+					pages.AddPage("modal", modal, true, true)
+				})
+			}()
+		}).
+		AddButton("Delete", func() {
+			RemoveEpisode(app, pages, showTitle, info.Title, info.Author)
+		}).
+		AddButton("cancel", func() {
+			pages.SwitchToPage("episodes")
+		}).
+		SetFocus(0)
+	currentIdx := 0
+	totalItem := form.GetFormItemCount() + form.GetButtonCount()
+	focusItem := func(idx int) {
+		if idx < 0 {
+			idx = totalItem - 1
+		} else if idx >= totalItem {
+			idx = 0
+		}
+		currentIdx = idx
+		if idx >= form.GetFormItemCount() {
+			app.SetFocus(form.GetButton(idx - form.GetFormItemCount()))
+		} else {
+			app.SetFocus(form.GetFormItem(idx))
+		}
+	}
+
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if typeDD.IsOpen() {
+			return event
+		}
+		switch event.Key() {
+		case tcell.KeyUp:
+			focusItem(currentIdx - 1)
+			return nil
+		case tcell.KeyDown:
+			focusItem(currentIdx + 1)
+			return nil
+		case tcell.KeyEsc:
+			pages.SwitchToPage("episodes")
+			return nil
+		}
+		return event
+	})
+	pages.AddAndSwitchToPage("episode", form, true)
+}
+
+func ListFailedVideos(app *tview.Application, pages *tview.Pages, showTitle string) {
+	failedInfos, err := rss.GetFailedVideos(showTitle)
+	if err != nil {
+		modal := ShowModal(fmt.Sprint(err), []string{"OK"}, func(_ int, _ string) {
+			pages.RemovePage("modal")
+		})
+		pages.AddPage("modal", modal, true, true)
+		return
+	}
+	failed := tview.NewList()
+	failed.SetTitle(fmt.Sprintf("Show %s Failed Videos", showTitle))
+	failed.AddItem("← Back", "Return to the Episodes", 'b', func() {
+		ListEpisodes(app, pages, showTitle)
+	})
+	if len(failedInfos) == 0 {
+		failed.AddItem(" No Failed Video ", "", 0, nil)
+	}
+	for _, info := range failedInfos {
+		status := fmt.Sprintf("%s, %d attempts, next retry %s", info.ErrorClass, info.Attempts, info.NextRetry.Format("2006-01-02 15:04"))
+		if info.Dead {
+			status = fmt.Sprintf("dead letter: %s, %d attempts", info.ErrorClass, info.Attempts)
+		}
+		failed.AddItem(info.Title, status, 0, func() {
+			RetryFailedVideo(app, pages, showTitle, info)
+		})
+	}
+	pages.AddAndSwitchToPage("failed", failed, true)
+}
+
+func RetryFailedVideo(app *tview.Application, pages *tview.Pages, showTitle string, info rss.FailedInfo) {
+	modal := ShowModal(fmt.Sprintf("\"%s\" failed with:\n%s\nDo you want to retry it now?", info.Title, info.Error), []string{"NO", "YES"}, func(_ int, label string) {
+		pages.RemovePage("modal")
+		if label != "YES" {
+			return
+		}
+		stop := ShowSpinnerModal(app, pages, "Retrying video...")
+		go func() {
+			_, err := rss.RetryVideo(showTitle, info.GUID)
+			stop()
+			app.QueueUpdateDraw(func() {
+				var modal *tview.Modal
+				if err == nil {
+					modal = ShowModal("Episode added successfully", []string{"OK"}, func(_ int, _ string) {
+						pages.RemovePage("modal")
+						ListFailedVideos(app, pages, showTitle)
+					})
+				} else {
+					modal = ShowModal(fmt.Sprintf("%v", err), []string{"OK"}, func(_ int, _ string) {
+						pages.RemovePage("modal")
+						ListFailedVideos(app, pages, showTitle)
+					})
+				}
+				pages.AddPage("modal", modal, true, true)
+			})
+		}()
+	})
+	pages.AddPage("modal", modal, true, true)
+}
+
+func ListExcludedEpisodes(app *tview.Application, pages *tview.Pages, showTitle string) {
+	excludedInfos, err := rss.GetExcludedVideos(showTitle)
+	if err != nil {
+		modal := ShowModal(fmt.Sprint(err), []string{"OK"}, func(_ int, _ string) {
+			pages.RemovePage("modal")
+		})
+		pages.AddPage("modal", modal, true, true)
+		return
+	}
+	excluded := tview.NewList()
+	excluded.SetTitle(fmt.Sprintf("Show %s Removed Episodes", showTitle))
+	excluded.AddItem("← Back", "Return to the Episodes", 'b', func() {
+		ListEpisodes(app, pages, showTitle)
+	})
+	if len(excludedInfos) == 0 {
+		excluded.AddItem(" No Removed Episode ", "", 0, nil)
+	}
+	for _, info := range excludedInfos {
+		reason := "removed manually"
+		if info.Reason == rss.EVICTED_BY_QUOTA {
+			reason = "evicted to free storage"
+		}
+		excluded.AddItem(info.Title, fmt.Sprintf("%s on %s", reason, info.RemovedOn.Format("2006-01-02")), 0, func() {
+			IncludeEpisode(app, pages, showTitle, info)
+		})
+	}
+	pages.AddAndSwitchToPage("excluded", excluded, true)
+}
+
+func IncludeEpisode(app *tview.Application, pages *tview.Pages, showTitle string, info rss.ExcludedInfo) {
+	modal := ShowModal(fmt.Sprintf("Do you want sync to add \"%s\" to the show again?", info.Title), []string{"NO", "YES"}, func(_ int, label string) {
+		pages.RemovePage("modal")
+		if label != "YES" {
+			return
+		}
+		var modal *tview.Modal
+		if err := rss.IncludeVideo(showTitle, info.GUID); err == nil {
+			modal = ShowModal("The video can be added again by sync", []string{"OK"}, func(_ int, _ string) {
+				pages.RemovePage("modal")
+				ListExcludedEpisodes(app, pages, showTitle)
+			})
+		} else {
+			modal = ShowModal(fmt.Sprintf("%v", err), []string{"OK"}, func(_ int, _ string) {
+				pages.RemovePage("modal")
+			})
+		}
+		pages.AddPage("modal", modal, true, true)
+	})
+	pages.AddPage("modal", modal, true, true)
+}
+
 func RemoveEpisode(app *tview.Application, pages *tview.Pages, showTitle, episodeTitle, author string) {
 	modal := ShowModal(fmt.Sprintf("Do you want to delete the episode titled \"%s\" from the show?", episodeTitle), []string{"NO", "YES"}, func(_ int, label string) {
 		switch label {
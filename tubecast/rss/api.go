@@ -1,23 +1,97 @@
 package rss
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
-func SyncChannel(title, channelID string) (string, error) {
+// SyncChannel subscribes the show to a channel handle, channel ID, channel tab or playlist and syncs it
+func SyncChannel(title, channel string, filter SubscriptionFilter) (string, error) {
+	if err := filter.validate(); err != nil {
+		return "", err
+	}
+	sub, err := parseSubscription(channel)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := sub.resolve(ctx); err != nil {
+		return "", err
+	}
+	sub.Filter = filter
+	metaStation, err := getMetaStation(title, "")
+	if err != nil {
+		return "", err
+	}
+	return metaStation.syncChannel(metaStation.subscribe(sub))
+}
+
+// SyncPodcast subscribes the show to the RSS feed of another podcast and mirrors its newest
+// episodes to our storage, or all of them when mirrorAll is set
+func SyncPodcast(title, feedUrl string, filter SubscriptionFilter, mirrorAll bool) (string, error) {
+	if err := filter.validate(); err != nil {
+		return "", err
+	}
+	u, err := url.Parse(strings.TrimSpace(feedUrl))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", errors.New("feed url must start with http:// or https://")
+	}
+	sub := Subscription{Kind: PODCAST_FEED, ID: u.String()}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if err := sub.resolve(ctx); err != nil {
+		return "", err
+	}
+	sub.Filter = filter
 	metaStation, err := getMetaStation(title, "")
 	if err != nil {
 		return "", err
 	}
-	metaStation.subscribeToChannel(channelID)
-	return metaStation.syncChannel(channelID)
+	if !mirrorAll {
+		return metaStation.syncChannel(metaStation.subscribe(sub))
+	}
+	return metaStation.mirrorPodcast(metaStation.subscribe(sub))
+}
+
+// BackfillChannel imports the upload history of a channel the show is subscribed to.
+// It is resumable: calling it again continues from the stored cursor.
+func BackfillChannel(title, channel string, oldestFirst bool) (string, error) {
+	if !StationNames.Has(title) {
+		return "", errors.New("show with this title does not exist")
+	}
+	metaStation, err := getMetaStation(title, "")
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	sub, err := metaStation.findSubscription(ctx, channel)
+	if err != nil {
+		return "", err
+	}
+	if sub.Kind == PODCAST_FEED {
+		// a feed lists all its episodes, there are no pages to walk
+		return metaStation.mirrorPodcast(sub)
+	}
+	if sub.Backfill == nil || sub.Backfill.OldestFirst != oldestFirst {
+		metaStation.updateSubscription(sub, func(sub *Subscription) {
+			sub.Backfill = &Backfill{OldestFirst: oldestFirst}
+		})
+	}
+	if err := metaStation.backfill(sub, 0); err != nil {
+		return "", err
+	}
+	return Megh.getShareableFeedUrl(title), nil
 }
 
 func CreateShow(title, description, coverFile string) (string, error) {
@@ -48,19 +122,110 @@ func CreateShow(title, description, coverFile string) (string, error) {
 	return Megh.getShareableFeedUrl(title), nil
 }
 
-func Sync() error {
+// Sync syncs all shows concurrently and reports the outcome per show and channel.
+// A failing channel does not stop the others.
+func Sync() SyncReport {
+	syncing.Lock()
+	defer syncing.Unlock()
+	var report SyncReport
+	var wg sync.WaitGroup
+	var mu sync.Mutex
 	for title := range StationNames.Value {
-		metaStation, err := getMetaStation(title, "")
-		if err != nil {
-			return err
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			show := syncShow(title)
+			mu.Lock()
+			defer mu.Unlock()
+			report.Shows = append(report.Shows, show)
+		}()
+	}
+	wg.Wait()
+	sort.Slice(report.Shows, func(i, j int) bool {
+		return report.Shows[i].Title < report.Shows[j].Title
+	})
+	return report
+}
+
+// held while all shows sync, so a scheduled sync does not overlap one started from the menu
+var syncing sync.Mutex
+
+// ScheduleSync syncs all shows every SYNC_INTERVAL in the background, when it is set
+func ScheduleSync() {
+	if SyncInterval <= 0 {
+		return
+	}
+	go func() {
+		for range time.Tick(SyncInterval) {
+			if !syncing.TryLock() {
+				continue
+			}
+			syncing.Unlock()
+			if report := Sync(); report.HasErrors() {
+				logError(errors.New(report.String()), "Scheduled Sync")
+			}
+		}
+	}()
+}
+
+func syncShow(title string) ShowReport {
+	report := ShowReport{Title: title}
+	metaStation, err := getMetaStation(title, "")
+	if err != nil {
+		report.Err = err
+		return report
+	}
+	for _, subscription := range append([]Subscription(nil), metaStation.Subscriptions...) {
+		sub := metaStation.getSubscription(subscription.getKey())
+		if sub == nil {
+			// removed while the show was syncing
+			continue
+		}
+		channel := metaStation.syncSubscription(sub)
+		// unfinished backfills advance a page per sync
+		if err := metaStation.backfill(sub, 1); err != nil && !errors.Is(err, errBackfillPaused) && channel.Err == nil {
+			channel.Err = err
 		}
-		for channel := range metaStation.SubscribedChannel.Value {
-			if _, err := metaStation.syncChannel(channel); err != nil {
-				return err
+		report.Channels = append(report.Channels, channel)
+	}
+	defer metaStation.lock()()
+	if _, err := metaStation.updateFeed(); err != nil {
+		report.Err = err
+	}
+	return report
+}
+
+func (report SyncReport) HasErrors() bool {
+	for _, show := range report.Shows {
+		if show.Err != nil {
+			return true
+		}
+		for _, channel := range show.Channels {
+			if channel.Err != nil || len(channel.Failed) > 0 {
+				return true
 			}
 		}
 	}
-	return nil
+	return false
+}
+
+func (report SyncReport) String() string {
+	var b strings.Builder
+	for _, show := range report.Shows {
+		fmt.Fprintf(&b, "%s", show.Title)
+		if show.Err != nil {
+			fmt.Fprintf(&b, ": %v", show.Err)
+		}
+		b.WriteString("\n")
+		for _, channel := range show.Channels {
+			if channel.Err != nil {
+				fmt.Fprintf(&b, "  %s: failed: %v\n", channel.Channel, channel.Err)
+				continue
+			}
+			fmt.Fprintf(&b, "  %s: %d added, %d failed\n", channel.Channel, channel.Added, len(channel.Failed))
+		}
+	}
+	return b.String()
 }
 
 func RemoveVideoFromShow(showTitle, videoTitle, author string) error {
@@ -74,6 +239,176 @@ func RemoveVideoFromShow(showTitle, videoTitle, author string) error {
 	return metaStation.deleteVideo(videoTitle, author)
 }
 
+// GetFailedVideos lists the videos of a show that failed, the dead-letter ones first
+func GetFailedVideos(title string) ([]FailedInfo, error) {
+	if !StationNames.Has(title) {
+		return nil, errors.New("show with this title does not exist")
+	}
+	metaStation, err := getMetaStation(title, "")
+	if err != nil {
+		return nil, err
+	}
+	return metaStation.getFailedItems(), nil
+}
+
+// RetryVideo tries a failed or dead-letter video again right away
+func RetryVideo(title, guid string) (string, error) {
+	if !StationNames.Has(title) {
+		return "", errors.New("show with this title does not exist")
+	}
+	metaStation, err := getMetaStation(title, "")
+	if err != nil {
+		return "", err
+	}
+	return metaStation.retryFailedItem(guid)
+}
+
+// GetExcludedVideos lists the videos removed from a show that Sync will not add again
+func GetExcludedVideos(title string) ([]ExcludedInfo, error) {
+	if !StationNames.Has(title) {
+		return nil, errors.New("show with this title does not exist")
+	}
+	metaStation, err := getMetaStation(title, "")
+	if err != nil {
+		return nil, err
+	}
+	return metaStation.getExcludedItems(), nil
+}
+
+// IncludeVideo lets Sync add a removed video again
+func IncludeVideo(title, guid string) error {
+	if !StationNames.Has(title) {
+		return errors.New("show with this title does not exist")
+	}
+	metaStation, err := getMetaStation(title, "")
+	if err != nil {
+		return err
+	}
+	defer metaStation.lock()()
+	if err := metaStation.unexclude(guid); err != nil {
+		return err
+	}
+	return metaStation.saveMetaStationToLocal()
+}
+
+// SetShowPodcastTags sets the podcast:locked, podcast:funding and podcast:person tags of a show.
+// Without persons the subscribed channels are listed as hosts.
+func SetShowPodcastTags(title string, locked bool, funding []Funding, persons []Person) (string, error) {
+	if !StationNames.Has(title) {
+		return "", errors.New("show with this title does not exist")
+	}
+	metaStation, err := getMetaStation(title, "")
+	if err != nil {
+		return "", err
+	}
+	defer metaStation.lock()()
+	metaStation.PodcastLocked = locked
+	metaStation.PodcastFunding = funding
+	metaStation.PodcastPersons = persons
+	station := metaStation.getStation()
+	if err := station.validatePodcast(); err != nil {
+		return "", err
+	}
+	return metaStation.updateFeed()
+}
+
+// SetEpisodePodcastTags sets the podcast:season, podcast:episode and podcast:person tags of an episode,
+// nil clears them. Without persons the channel that uploaded the video is listed as host.
+func SetEpisodePodcastTags(title, guid string, season *PodcastSeason, episode *PodcastEpisode, persons []Person) (string, error) {
+	if !StationNames.Has(title) {
+		return "", errors.New("show with this title does not exist")
+	}
+	metaStation, err := getMetaStation(title, "")
+	if err != nil {
+		return "", err
+	}
+	defer metaStation.lock()()
+	for i := range metaStation.Items {
+		if metaStation.Items[i].GUID != guid {
+			continue
+		}
+		item := &metaStation.Items[i]
+		item.PodcastSeason = season
+		item.PodcastEpisode = episode
+		item.PodcastPersons = persons
+		stationItem := getStationItem(*item)
+		if err := stationItem.validatePodcast(); err != nil {
+			return "", err
+		}
+		return metaStation.updateFeed()
+	}
+	return "", errors.New("episode with this guid does not exist in the show")
+}
+
+// GetShowNumbering returns the itunes:type of a show and how its episodes are numbered
+func GetShowNumbering(title string) (string, NumberingPolicy, error) {
+	if !StationNames.Has(title) {
+		return "", "", errors.New("show with this title does not exist")
+	}
+	metaStation, err := getMetaStation(title, "")
+	if err != nil {
+		return "", "", err
+	}
+	policy := metaStation.Numbering
+	if len(policy) == 0 {
+		policy = NUMBER_MANUALLY
+	}
+	return metaStation.getITunesType(), policy, nil
+}
+
+// SetShowNumbering sets the itunes:type of a show, episodic or serial, and its numbering policy.
+// Switching to an automatic policy renumbers the episodes once, later episodes are slotted in by publication date.
+func SetShowNumbering(title, showType string, policy NumberingPolicy) (string, error) {
+	if err := validateNumbering(showType, policy); err != nil {
+		return "", err
+	}
+	if !StationNames.Has(title) {
+		return "", errors.New("show with this title does not exist")
+	}
+	metaStation, err := getMetaStation(title, "")
+	if err != nil {
+		return "", err
+	}
+	defer metaStation.lock()()
+	metaStation.ITunesType = showType
+	if metaStation.Numbering != policy {
+		metaStation.Numbering = policy
+		metaStation.renumberEpisodes()
+	}
+	return metaStation.updateFeed()
+}
+
+// SetEpisodeNumbering sets the type of an episode, full, trailer or bonus, and its season and
+// episode numbers. The numbers are kept only by shows numbered manually, 0 leaves them out.
+// Under an automatic policy an episode that becomes a full episode gets the next number.
+func SetEpisodeNumbering(title, guid, episodeType string, season, episode int) (string, error) {
+	if err := validateEpisodeNumbering(episodeType, season, episode); err != nil {
+		return "", err
+	}
+	if !StationNames.Has(title) {
+		return "", errors.New("show with this title does not exist")
+	}
+	metaStation, err := getMetaStation(title, "")
+	if err != nil {
+		return "", err
+	}
+	defer metaStation.lock()()
+	for i := range metaStation.Items {
+		if item := &metaStation.Items[i]; item.GUID == guid {
+			if !metaStation.isNumberedAutomatically() {
+				item.ITunesEpisodeType = episodeType
+				item.ITunesSeason = season
+				item.ITunesEpisode = episode
+			} else if item.getITunesEpisodeType() != episodeType {
+				item.ITunesEpisodeType = episodeType
+				metaStation.numberEpisode(item)
+			}
+			return metaStation.updateFeed()
+		}
+	}
+	return "", errors.New("episode with this guid does not exist in the show")
+}
+
 func RemoveShow(title string) error {
 	if !StationNames.Has(title) {
 		return errors.New("show with this title does not exist")
@@ -93,6 +428,22 @@ func AddVideoToShow(title, videoUrl string) (string, error) {
 	return metaStation.addVideo(videoUrl)
 }
 
+// AddFileToShow publishes a local audio or video file as an episode. Relative paths are
+// looked up in tubecast/files, artwork is optional.
+func AddFileToShow(title, path, episodeTitle, description, artwork string) (string, error) {
+	if !StationNames.Has(title) {
+		return "", errors.New("show with this title does not exist")
+	}
+	if len(strings.TrimSpace(episodeTitle)) == 0 {
+		return "", errors.New("episode title is empty")
+	}
+	metaStation, err := getMetaStation(title, "")
+	if err != nil {
+		return "", err
+	}
+	return metaStation.addFile(path, episodeTitle, description, artwork)
+}
+
 func GetAllShowEpisodes(title string) ([]EpisodeInfo, error) {
 	if !StationNames.Has(title) {
 		return nil, errors.New("Show with the title does not exist")
@@ -121,6 +472,81 @@ func GetFeedUrl(title string) string {
 	return Megh.getShareableFeedUrl(title)
 }
 
+// GetAtomFeedUrl returns the Atom 1.0 feed of a show, for feed readers
+func GetAtomFeedUrl(title string) string {
+	return Megh.getShareableAtomFeedUrl(title)
+}
+
+// GetJsonFeedUrl returns the JSON Feed 1.1 document of a show, for feed readers
+func GetJsonFeedUrl(title string) string {
+	return Megh.getShareableJsonFeedUrl(title)
+}
+
+// ExportShows writes an OPML 2.0 file listing the feed of every show and returns its URL.
+// Podcast apps subscribe to all shows from it; another install recreates them with ImportShows.
+func ExportShows() (string, error) {
+	return exportOpml()
+}
+
+// ImportShows creates shows and subscriptions from an OPML file, such as a YouTube subscriptions
+// export or the export of another install. Relative paths are looked up in tubecast/files.
+func ImportShows(path string) (ImportReport, error) {
+	if len(strings.TrimSpace(path)) == 0 {
+		return ImportReport{}, errors.New("opml file is empty")
+	}
+	return importOpml(path)
+}
+
+// SetFeedItemLimit caps the number of episodes in the main feed of a show. Older episodes are
+// published in archive pages linked from it; 0 falls back to FEED_ITEM_LIMIT.
+func SetFeedItemLimit(title string, limit int) (string, error) {
+	if limit < 0 {
+		return "", errors.New("feed item limit cannot be negative")
+	}
+	if !StationNames.Has(title) {
+		return "", errors.New("show with this title does not exist")
+	}
+	metaStation, err := getMetaStation(title, "")
+	if err != nil {
+		return "", err
+	}
+	defer metaStation.lock()()
+	metaStation.FeedItemLimit = limit
+	return metaStation.updateFeed()
+}
+
+// SetFeedTTL sets the minutes podcast apps may cache the feed of a show, 0 follows the sync interval again
+func SetFeedTTL(title string, minutes int) (string, error) {
+	if minutes < 0 {
+		return "", errors.New("ttl cannot be negative")
+	}
+	if !StationNames.Has(title) {
+		return "", errors.New("show with this title does not exist")
+	}
+	metaStation, err := getMetaStation(title, "")
+	if err != nil {
+		return "", err
+	}
+	defer metaStation.lock()()
+	metaStation.TTL = minutes
+	return metaStation.updateFeed()
+}
+
+// ValidateShow checks the feed of a show against the rules of Apple Podcasts and Podcast Index
+func ValidateShow(title string) (FeedReport, error) {
+	if !StationNames.Has(title) {
+		return FeedReport{}, errors.New("show with this title does not exist")
+	}
+	metaStation, err := getMetaStation(title, "")
+	if err != nil {
+		return FeedReport{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	station := metaStation.getStation()
+	return station.validateRemote(ctx), nil
+}
+
 func (station *Station) Print() {
 	fmt.Println("------ Station Print ------")
 	fmt.Printf("ID: %v\n", station.ID)
@@ -134,6 +560,11 @@ func (station *Station) Print() {
 	fmt.Printf("ITunesImage: %v\n", station.ITunesImage)
 	fmt.Printf("ITunesExplicit: %v\n", station.ITunesExplicit)
 	fmt.Printf("ITunesCategories: %v\n", station.ITunesCategories)
+	fmt.Printf("ITunesType: %v\n", station.ITunesType)
+	fmt.Printf("PodcastGUID: %v\n", station.PodcastGUID)
+	fmt.Printf("PodcastLocked: %v\n", station.PodcastLocked)
+	fmt.Printf("PodcastFunding: %v\n", station.PodcastFunding)
+	fmt.Printf("PodcastPersons: %v\n", station.PodcastPersons)
 	for i, item := range station.Items {
 		fmt.Printf("Item %v:\n", i)
 		item.Print()
@@ -150,17 +581,22 @@ func (stationItem *StationItem) Print() {
 	fmt.Printf("PubDate: %v\n", stationItem.PubDate)
 	fmt.Printf("ITunesDuration: %v\n", stationItem.ITunesDuration)
 	fmt.Printf("ITunesExplicit: %v\n", stationItem.ITunesExplicit)
-	// fmt.Printf("ITunesEpisode: %v\n", stationItem.ITunesEpisode)
-	// fmt.Printf("ITunesSeason: %v\n", stationItem.ITunesSeason)
-	// fmt.Printf("ITunesEpisodeType: %v\n", stationItem.ITunesEpisodeType)
+	fmt.Printf("ITunesEpisode: %v\n", stationItem.ITunesEpisode)
+	fmt.Printf("ITunesSeason: %v\n", stationItem.ITunesSeason)
+	fmt.Printf("ITunesEpisodeType: %v\n", stationItem.ITunesEpisodeType)
 	fmt.Println("----------------- ---------------------")
 }
 
 func Init() {
 	Usr = User{
 		Username: os.Getenv("USERNAME"),
+		Name:     os.Getenv("OWNER_NAME"),
+		EmailId:  os.Getenv("OWNER_EMAIL"),
 	}
 	Usr.Username = strings.ToLower(Usr.Username)
+	if len(Usr.Name) == 0 {
+		Usr.Name = Usr.Username
+	}
 	// fmt.Printf("username: %v\n", Usr.Username)
 	Megh = Cloud{
 		ArchiveId:        Usr.getArchiveIdentifier(),
@@ -172,7 +608,48 @@ func Init() {
 	if isArch == "Yes" {
 		Megh.IsArchive = true
 	}
+	FeedItemLimit = getEnvInt("FEED_ITEM_LIMIT", FeedItemLimit)
+	SyncInterval = getEnvDuration("SYNC_INTERVAL", SyncInterval)
+	if os.Getenv("STRICT_VALIDATION") == "Yes" {
+		StrictValidation = true
+	}
+	if os.Getenv("ATOM_DISCOVERY") == "Yes" {
+		AtomDiscovery = true
+	}
+	if feedBase := os.Getenv("YOUTUBE_FEED_BASE"); len(feedBase) > 0 {
+		YOUTUBE_FEED_BASE = feedBase
+	}
+	Cookies = parseCookies(os.Getenv("COOKIES"))
+	network := NetworkProfile{
+		Proxy:           os.Getenv("PROXY"),
+		RateLimit:       os.Getenv("DOWNLOAD_RATE_LIMIT"),
+		UserAgent:       Network.UserAgent,
+		Retries:         getEnvInt("RETRIES", Network.Retries),
+		FragmentRetries: getEnvInt("FRAGMENT_RETRIES", Network.FragmentRetries),
+	}
+	if userAgent := os.Getenv("USER_AGENT"); len(userAgent) > 0 {
+		network.UserAgent = userAgent
+	}
+	if err := network.validate(); err != nil {
+		logError(err, "Init - Network Profile")
+	} else {
+		Network = network
+	}
+	MaximumDuration = getEnvDuration("MAX_DURATION", MaximumDuration)
+	SplitDuration = getEnvDuration("SPLIT_DURATION", SplitDuration)
+	MaxAttempts = getEnvInt("MAX_ATTEMPTS", MaxAttempts)
+	RetryBackoff = getEnvDuration("RETRY_BACKOFF", RetryBackoff)
+	Jobs = NewScheduler(
+		getEnvInt("METADATA_WORKERS", 4),
+		getEnvInt("DOWNLOAD_WORKERS", 2),
+		getEnvInt("UPLOAD_WORKERS", 2),
+		getEnvFloat("RATE_LIMIT", 0),
+		getEnvFloat("HOST_RATE_LIMIT", 2),
+	)
 	if err := loadAllMetaStationNames(); err != nil {
 		// fmt.Printf("error in init: %v\n", err)
 	}
+	Media = loadMediaStore()
+	// jobs can take minutes each, the TUI starts meanwhile
+	go resumeIngestJobs()
 }
@@ -0,0 +1,105 @@
+package rss
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+const BACKFILL_PAGE_SIZE = 10
+
+// Backfill never evicts episodes, it pauses once storage is this full
+const BACKFILL_STORAGE_LIMIT = 0.9
+
+var (
+	errBackfillPaused = errors.New("backfill paused")
+	errBackfillQuota  = fmt.Errorf("%w: storage quota reached", errBackfillPaused)
+	errBackfillRetry  = fmt.Errorf("%w: waiting to retry a failed video", errBackfillPaused)
+)
+
+// getBackfillItems returns the --playlist-items range of the next page
+func (backfill *Backfill) getBackfillItems() string {
+	if backfill.OldestFirst {
+		return fmt.Sprintf("-%d:-%d", backfill.Cursor+BACKFILL_PAGE_SIZE, backfill.Cursor+1)
+	}
+	return fmt.Sprintf("%d:%d", backfill.Cursor+1, backfill.Cursor+BACKFILL_PAGE_SIZE)
+}
+
+// backfill walks the upload history of sub for at most the given number of pages, all of them when pages is 0.
+// The cursor is saved after every entry so an interrupted backfill resumes where it stopped. It stays on a
+// video that failed until the video is added or moved to the dead-letter list.
+func (metaStation *MetaStation) backfill(sub *Subscription, pages int) error {
+	if sub.Backfill == nil {
+		return nil
+	}
+	for page := 0; sub.Backfill != nil && !sub.Backfill.Done && (pages == 0 || page < pages); page++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		entries, err := listVideos(ctx, sub.getFeedUrl(), sub.Backfill.getBackfillItems())
+		cancel()
+		if err != nil {
+			logError(err, "Backfill - List Videos")
+			return err
+		}
+		if sub.Backfill.OldestFirst {
+			slices.Reverse(entries)
+		}
+		for _, source := range entries {
+			if !Megh.hasBackfillSpace() {
+				return errBackfillQuota
+			}
+			if err := metaStation.backfillVideo(sub, source); err != nil {
+				return err
+			}
+			if !metaStation.isSettled(source.getGUID()) {
+				return errBackfillRetry
+			}
+			if !metaStation.saveBackfill(sub, func(backfill *Backfill) { backfill.Cursor++ }) {
+				// the subscription was replaced meanwhile
+				return nil
+			}
+		}
+		if len(entries) < BACKFILL_PAGE_SIZE {
+			metaStation.saveBackfill(sub, func(backfill *Backfill) { backfill.Done = true })
+		}
+	}
+	return nil
+}
+
+// backfillVideo adds the video unless the show has it or the filter rejects it.
+// The episode is published without evicting older ones.
+func (metaStation *MetaStation) backfillVideo(sub *Subscription, source VideoSource) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+	selected, err := metaStation.selectVideos(ctx, []VideoSource{source}, sub.getFeedUrl(), sub)
+	if err != nil || len(selected) == 0 {
+		return err
+	}
+	job := getIngestJob(metaStation.Title, source, sub.getAuthor(ctx, source), sub.getFeedUrl())
+	job.Backfill = true
+	if _, err := metaStation.runIngestJob(ctx, job); err != nil {
+		logError(err, "Backfill - Add item to Station")
+	}
+	return nil
+}
+
+// saveBackfill applies change to the backfill of sub and saves it, and reports whether sub still has a backfill
+func (metaStation *MetaStation) saveBackfill(sub *Subscription, change func(*Backfill)) bool {
+	metaStation.updateSubscription(sub, func(sub *Subscription) {
+		if sub.Backfill != nil {
+			change(sub.Backfill)
+		}
+	})
+	return sub.Backfill != nil
+}
+
+func (cloud *Cloud) hasBackfillSpace() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	usage, err := cloud.getUsage(ctx)
+	if err != nil {
+		return false
+	}
+	return float64(usage.TotalSizeBytes) < float64(cloud.MaximumStorage)*BACKFILL_STORAGE_LIMIT
+}
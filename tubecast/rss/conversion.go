@@ -5,38 +5,112 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 )
 
-func (metaStation *MetaStation) syncChannel(channelUsername string) (string, error) {
-	channelFeedUrl, err := GetChannelFeedUrl(channelUsername)
-	if err != nil {
-		return "", err
+func (metaStation *MetaStation) syncChannel(sub *Subscription) (string, error) {
+	if report := metaStation.syncSubscription(sub); report.Err != nil {
+		return "", report.Err
 	}
+	defer metaStation.lock()()
+	return metaStation.updateFeed()
+}
+
+// syncSubscription adds the new videos of sub concurrently and reports the outcome per video
+func (metaStation *MetaStation) syncSubscription(sub *Subscription) ChannelReport {
+	if sub.Kind == PODCAST_FEED {
+		// episodes are downloaded whole, so this takes longer than finding new videos
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		defer cancel()
+		return metaStation.syncPodcastFeed(ctx, sub, 3)
+	}
+	report := ChannelReport{Channel: sub.getDisplayName()}
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
+	if !isChannelId(sub.ID) && sub.Kind != PLAYLIST && sub.Kind != SOURCE_URL {
+		resolved := *sub
+		if err := resolved.resolve(ctx); err != nil {
+			logError(err, "syncChannel - Resolve")
+			report.Err = err
+			return report
+		}
+		metaStation.updateSubscription(sub, func(sub *Subscription) {
+			sub.ID, sub.Name = resolved.ID, resolved.Name
+		})
+	}
+	channelFeedUrl := sub.getFeedUrl()
 
-	ids, err := metaStation.getLatestVideos(ctx, channelFeedUrl, 3)
-	if err != nil {
-		logError(err, "syncChannel - Get Latest Videos")
-		return "", err
+	var latest []VideoSource
+	var validators atomValidators
+	var err error
+	discovered := false
+	if AtomDiscovery && sub.supportsAtomDiscovery() {
+		// falls back to yt-dlp when the feed cannot be read
+		if latest, validators, err = sub.discoverLatestVideos(ctx, 3); err != nil {
+			logError(err, "syncChannel - Atom Discovery")
+		} else {
+			discovered = true
+		}
 	}
-	// fmt.Println("Latest video urls to be uploaded: ", ids)
-	for _, id := range ids {
-		if _, err := metaStation.addItemToStation(ctx, id, channelUsername, channelFeedUrl); err != nil {
-			logError(err, "syncChannel - Add item to Station")
+	if !discovered {
+		err = Jobs.do(ctx, METADATA, channelFeedUrl, func() (err error) {
+			latest, err = listLatestVideos(ctx, channelFeedUrl, 3)
+			return err
+		})
+		if err != nil {
+			logError(err, "syncChannel - Get Latest Videos")
+			report.Err = err
+			return report
 		}
 	}
-	return metaStation.updateFeed()
+	sources, err := metaStation.selectVideos(ctx, latest, channelFeedUrl, sub)
+	if err != nil {
+		logError(err, "syncChannel - Select Videos")
+		report.Err = err
+		return report
+	}
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for _, source := range sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := metaStation.addItemToStation(ctx, source, sub.getAuthor(ctx, source), channelFeedUrl)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				logError(err, "syncChannel - Add item to Station")
+				report.Failed = append(report.Failed, fmt.Sprintf("%s: %v", source.ID, err))
+			} else {
+				report.Added++
+			}
+		}()
+	}
+	wg.Wait()
+	// an unchanged feed is skipped, so failed videos keep the old validators until they are retried
+	settled := len(report.Failed) == 0
+	for _, source := range latest {
+		settled = settled && metaStation.isSettled(source.getGUID())
+	}
+	if settled && (len(validators.ETag) > 0 || len(validators.LastModified) > 0) {
+		metaStation.updateSubscription(sub, func(sub *Subscription) {
+			sub.ETag = validators.ETag
+			sub.LastModified = validators.LastModified
+		})
+	}
+	return report
 }
 
 func (metaStation *MetaStation) deleteVideo(videoTitle, author string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
+	defer metaStation.lock()()
 
 	var index int = -1
 	for i, item := range metaStation.Items {
@@ -48,20 +122,38 @@ func (metaStation *MetaStation) deleteVideo(videoTitle, author string) error {
 	if index == -1 {
 		return errors.New("video does not exist in this show")
 	}
-	if err := Megh.deleteEpisode(ctx, metaStation.Items[index].GUID, metaStation.Title); err != nil {
+	if _, err := metaStation.deleteItemFiles(ctx, metaStation.Items[index]); err != nil {
 		return err
 	}
+	metaStation.exclude(metaStation.Items[index], REMOVED_MANUALLY)
 	metaStation.Items = append(metaStation.Items[:index], metaStation.Items[index+1:]...)
+	metaStation.ItemsChangedOn = time.Now()
 	metaStation.updateFeed()
 	// fmt.Printf("file deleted with id %v\n", id)
 	return nil
 }
 
 func (metaStation *MetaStation) delete() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	for _, item := range metaStation.Items {
+		if len(item.MediaKey) > 0 {
+			if _, err := metaStation.releaseMedia(ctx, item.MediaKey); err != nil {
+				logError(err, "Delete Show - Release Media")
+			}
+		}
+	}
 	if err := Megh.deleteShow(metaStation.Title); err != nil {
 		return err
 	}
 	os.Remove(Megh.getLocalFeedFilepath(metaStation.Title))
+	os.Remove(Megh.getLocalAtomFeedFilepath(metaStation.Title))
+	os.Remove(Megh.getLocalJsonFeedFilepath(metaStation.Title))
+	for number := 1; ; number++ {
+		if err := os.Remove(Megh.getLocalFeedPageFilepath(metaStation.Title, number)); err != nil {
+			break
+		}
+	}
 	os.Remove(Megh.getLocalStationFilepath(metaStation.Title))
 	StationNames.Remove(metaStation.Title)
 	return nil
@@ -71,193 +163,336 @@ func (metaStation *MetaStation) addVideo(videoUrl string) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
-	id, err := getVideoId(ctx, videoUrl)
+	source, err := getVideoSource(ctx, videoUrl)
 	if err != nil {
 		return "", err
 	}
-	ids := metaStation.filter([]string{id})
+	ids := metaStation.filter([]string{source.getGUID()})
 	// fmt.Printf("ids...\n")
 	if len(ids) == 0 {
 		return "", errors.New("video already exists in the channel")
 	}
+	// adding a removed video by hand brings it back for good
+	if metaStation.isExcluded(source.getGUID()) {
+		unlock := metaStation.lock()
+		delete(metaStation.Excluded, source.getGUID())
+		metaStation.saveMetaStationToLocal()
+		unlock()
+	}
 	// fmt.Printf("username starting...\n")
-	username, err := getVideoUsername(ctx, videoUrl)
+	username, err := getVideoUsername(ctx, source.Url)
 	if err != nil {
 		return "", err
 	}
-	channelFeedUrl, err := GetChannelFeedUrl(username)
+	var channelFeedUrl string
+	if source.isYoutube() {
+		channelFeedUrl, err = GetChannelFeedUrl(username)
+	} else {
+		channelFeedUrl, err = getVideoChannelUrl(ctx, source.Url)
+	}
 	if err != nil {
 		return "", err
 	}
-	if share, err := metaStation.addItemToStation(ctx, id, username, channelFeedUrl); err != nil {
+	if share, err := metaStation.addItemToStation(ctx, source, username, channelFeedUrl); err != nil {
 		return "", err
 	} else {
 		return share, nil
 	}
 }
 
-func (metaStation *MetaStation) addItemToStation(ctx context.Context, id, username, channelFeedUrl string) (string, error) {
-	metaStationItem := MetaStationItem{
-		GUID:           id,
-		ITunesAuthor:   username,
-		ChannelID:      channelFeedUrl,
-		AddedOn:        time.Now(),
-		ITunesExplicit: "no",
-		Link:           "https://www.youtube.com/watch?v=" + id,
+func (metaStation *MetaStation) addItemToStation(ctx context.Context, source VideoSource, username, channelFeedUrl string) (string, error) {
+	job := getIngestJob(metaStation.Title, source, username, channelFeedUrl)
+	return metaStation.runIngestJob(ctx, job)
+}
+
+// runIngestJob takes the job from the state it was left in to committed, saving it after every step
+func (metaStation *MetaStation) runIngestJob(ctx context.Context, job *IngestJob) (string, error) {
+	unlock, ok := job.tryLock()
+	if !ok {
+		return "", errJobRunning
+	}
+	defer unlock()
+	job.Attempts++
+	if !job.isDownloaded() {
+		job.setState(JOB_DOWNLOADING)
+		if err := metaStation.downloadItem(ctx, job); err != nil {
+			metaStation.releaseJobMedia(ctx, job)
+			return "", job.fail(err)
+		}
 	}
-	if err := isValidForDownload(ctx, metaStationItem.Link); err != nil {
-		return "", err
+	job.setState(JOB_UPLOADING)
+	share, err := metaStation.publishItem(ctx, job)
+	if err != nil {
+		metaStation.releaseJobMedia(ctx, job)
+		return "", job.fail(err)
+	}
+	job.commit()
+	return share, nil
+}
+
+// releaseJobMedia drops the reference a failed job holds on stored audio, unless its episode
+// made it into the station. The next attempt downloads or reuses the audio again.
+func (metaStation *MetaStation) releaseJobMedia(ctx context.Context, job *IngestJob) {
+	if len(job.Item.MediaKey) == 0 {
+		return
+	}
+	unlock := metaStation.lock()
+	committed := metaStation.HasItem(job.Item.GUID)
+	unlock()
+	if committed {
+		return
+	}
+	if _, err := metaStation.releaseMedia(ctx, job.Item.MediaKey); err != nil {
+		logError(err, "Release Job Media")
+	}
+	job.Item.MediaKey = ""
+	job.Item.Enclosure = Enclosure{}
+	job.AudioSize = 0
+}
+
+// downloadItem fetches the metadata, thumbnail and audio of the job's video
+func (metaStation *MetaStation) downloadItem(ctx context.Context, job *IngestJob) error {
+	if job.Source.Extractor == PODCAST_EXTRACTOR {
+		return metaStation.downloadEnclosure(ctx, job)
+	}
+	metaStationItem := &job.Item
+	err := Jobs.do(ctx, METADATA, metaStationItem.Link, func() (err error) {
+		job.Duration, err = isValidForDownload(ctx, metaStationItem.Link)
+		return err
+	})
+	if err != nil {
+		return err
 	}
 	var wg sync.WaitGroup
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		if title, err := getVideoTitle(ctx, metaStationItem.Link); err != nil {
-			return
-		} else {
-			metaStationItem.Title = title
-		}
-	}()
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		if description, err := getVideoDescription(ctx, metaStationItem.Link); err != nil {
-			// fmt.Printf("Error: %v\n", err)
-			return
-		} else {
-			description = "Link to the YouTube Video: " + metaStationItem.Link + "\n" + description
-			metaStationItem.Description = description
-			metaStationItem.ITunesSubtitle = description
-		}
-	}()
-	wg.Add(1)
-	go func() {
-		wg.Done()
-		if duration, err := getVideoDuration(ctx, metaStationItem.Link); err != nil {
-			// fmt.Printf("Error: %v\n", err)
-			return
-		} else {
-			metaStationItem.ITunesDuration = duration
-		}
-	}()
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		if views, err := getVideoViews(ctx, metaStationItem.Link); err != nil {
-			// fmt.Printf("Error: %v\n", err)
-			return
-		} else {
-			metaStationItem.Views = views
-		}
-	}()
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		if pubDate, err := getVideoPubDate(ctx, metaStationItem.Link); err != nil {
-			// fmt.Printf("Error: %v\n", err)
-			return
-		} else {
-			metaStationItem.PubDate = pubDate
+	var mu sync.Mutex
+	var errs []error
+	// every fetch runs on the scheduler's pool for its stage, an episode is not published with missing metadata
+	fetch := func(stage Stage, fn func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := Jobs.do(ctx, stage, metaStationItem.Link, fn); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}()
+	}
+	fetch(METADATA, func() error {
+		title, err := getVideoTitle(ctx, metaStationItem.Link)
+		metaStationItem.Title = title
+		return err
+	})
+	fetch(METADATA, func() error {
+		description, err := getVideoDescription(ctx, metaStationItem.Link)
+		if err != nil {
+			return err
 		}
-	}()
+		// the show notes credit the original, see getShowNotes
+		metaStationItem.Description = description
+		return nil
+	})
+	fetch(METADATA, func() error {
+		duration, err := getVideoDuration(ctx, metaStationItem.Link)
+		metaStationItem.ITunesDuration = duration
+		return err
+	})
+	fetch(METADATA, func() error {
+		views, err := getVideoViews(ctx, metaStationItem.Link)
+		metaStationItem.Views = views
+		return err
+	})
+	fetch(METADATA, func() error {
+		pubDate, err := getVideoPubDate(ctx, metaStationItem.Link)
+		metaStationItem.PubDate = pubDate
+		return err
+	})
+	job.ThumbnailError = ""
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if err := metaStationItem.saveVideoThumbnail(ctx, metaStation.Title, metaStationItem.Link); err != nil {
-			// fmt.Printf("Error: %v\n", err)
-			return
+		err := Jobs.do(ctx, METADATA, metaStationItem.Link, func() error {
+			return metaStationItem.saveVideoThumbnail(ctx, metaStation.Title, metaStationItem.Link)
+		})
+		if err != nil {
+			// the episode falls back to the show cover, see publishItem
+			job.ThumbnailError = err.Error()
 		}
 	}()
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		if size, err := metaStationItem.saveAudio(ctx, metaStation.Title, metaStationItem.Link, 0); err != nil {
-			// fmt.Printf("Error: %v\n", err)
-			return
-		} else {
-			metaStation.makeSpace(ctx, size)
-			if share, err := Megh.upload(ctx, metaStationItem.GUID, metaStation.Title, AUDIO); err != nil {
-				// fmt.Printf("audio error: %v\n", err)
-				return
-			} else {
-				metaStationItem.Enclosure = Enclosure{
-					URL:    share,
-					Type:   "audio/mpeg",
-					Length: size,
-				}
-				if share, err := Megh.upload(ctx, metaStationItem.GUID, metaStation.Title, THUMBNAIL); err != nil {
-					// fmt.Printf("thumbnail error: %v\n", err)
-					return
-				} else {
-					metaStationItem.ITunesImage = ITunesImage{
-						Href: share,
-					}
-				}
+	var audioErr error
+	key := getMediaKey(metaStationItem.GUID, metaStation.getAudioProfile())
+	var enclosure Enclosure
+	var shared bool
+	// the parts of a split video are cut from the download
+	if !job.isSplit() {
+		enclosure, shared = metaStation.acquireMedia(key)
+	}
+	if shared {
+		// another show has the video already
+		metaStationItem.MediaKey = key
+		metaStationItem.Enclosure = enclosure
+		job.AudioSize = enclosure.Length
+	} else {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			audioErr = Jobs.do(ctx, DOWNLOAD, metaStationItem.Link, func() (err error) {
+				job.AudioSize, err = metaStationItem.saveAudio(ctx, metaStation.Title, metaStationItem.Link, metaStation.getAudioProfile().Quality)
+				return err
+			})
+		}()
+	}
+	wg.Wait()
+	return errors.Join(append(errs, audioErr)...)
+}
 
+// publishItem uploads what has not been uploaded yet and adds the episode to the station
+func (metaStation *MetaStation) publishItem(ctx context.Context, job *IngestJob) (string, error) {
+	metaStationItem := &job.Item
+	if !job.Tagged && len(metaStationItem.Enclosure.URL) == 0 {
+		// the thumbnail is embedded, so this runs before it is uploaded
+		err := Jobs.do(ctx, DOWNLOAD, metaStationItem.Link, func() error {
+			size, err := metaStation.tagAudio(ctx, *metaStationItem)
+			if err == nil {
+				job.AudioSize = size
 			}
+			return err
+		})
+		if err != nil {
+			// untagged audio still plays
+			logError(err, "Publish Item - Tag Audio")
+		} else {
+			job.Tagged = true
+			job.save()
 		}
-	}()
-	wg.Wait()
+	}
+	if job.isSplit() {
+		if len(metaStationItem.ITunesImage.Href) == 0 {
+			metaStationItem.ITunesImage = metaStation.getJobThumbnail(ctx, job)
+			job.save()
+		}
+		return metaStation.addPartsToStation(ctx, *metaStationItem, job.Duration, !job.Backfill)
+	}
 	if len(metaStationItem.Enclosure.URL) == 0 {
-		return "", errors.New("could not upload audio")
+		key, enclosure, err := metaStation.storeMedia(ctx, metaStationItem.GUID, job.AudioSize, !job.Backfill)
+		if err != nil {
+			return "", err
+		}
+		metaStationItem.MediaKey = key
+		metaStationItem.Enclosure = enclosure
+		job.save()
+	}
+	if len(metaStationItem.ITunesImage.Href) == 0 {
+		metaStationItem.ITunesImage = metaStation.getJobThumbnail(ctx, job)
+	}
+	defer metaStation.lock()()
+	if !metaStation.HasItem(metaStationItem.GUID) {
+		metaStation.addToStation(*metaStationItem)
 	}
-	metaStation.addToStation(metaStationItem)
 	return metaStation.updateFeed()
 }
 
-func (metaStation *MetaStation) getLatestVideos(ctx context.Context, channelUrl string, limit uint) ([]string, error) {
+// getJobThumbnail uploads the thumbnail of the job's episode, or uses the show cover when it could not be downloaded
+func (metaStation *MetaStation) getJobThumbnail(ctx context.Context, job *IngestJob) ITunesImage {
+	if len(job.ThumbnailError) > 0 {
+		logError(errors.New(job.ThumbnailError), "Publish Item - Thumbnail, using the show cover for "+job.Item.Link)
+		return metaStation.ITunesImage
+	}
+	return metaStation.uploadThumbnail(ctx, job.Item.GUID)
+}
+
+// uploadThumbnail uploads the thumbnail of an episode, falling back to the show cover when there is none
+func (metaStation *MetaStation) uploadThumbnail(ctx context.Context, guid string) ITunesImage {
+	share, err := Megh.upload(ctx, guid, metaStation.Title, THUMBNAIL)
+	if err != nil {
+		logError(err, "Upload Thumbnail - using the show cover")
+		return metaStation.ITunesImage
+	}
+	return ITunesImage{Href: share}
+}
+
+func listLatestVideos(ctx context.Context, channelUrl string, limit uint) ([]VideoSource, error) {
+	return listVideos(ctx, channelUrl, fmt.Sprintf("1:%d", limit))
+}
+
+// listVideos lists the entries of a channel or playlist selected by yt-dlp's --playlist-items syntax
+func listVideos(ctx context.Context, channelUrl string, items string) ([]VideoSource, error) {
 	args := []string{
-		"--get-id",
-		"--match-filter",
-		"live_status!='is_upcoming'",
-		"--playlist-end",
-		fmt.Sprint(limit),
-		"--user-agent",
-		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
-		"--referer",
-		"https://www.youtube.com/",
+		"--flat-playlist",
+		"--print",
+		"%(ie_key)s\t%(id)s\t%(url)s",
+		"--playlist-items",
+		items,
 		"--sleep-interval",
 		"1",
 		"--max-sleep-interval",
 		"3",
-		"--format",
-		"worst", // Use lower quality to avoid blocked formats
-		channelUrl,
 	}
+	if u, err := url.Parse(channelUrl); err == nil && isYoutubeUrl(u) {
+		args = append(args, "--referer", "https://www.youtube.com/")
+	}
+	args = append(args, channelUrl)
 
-	out, err := run(ctx, "yt-dlp", args...)
+	out, err := ytdlp(ctx, args...)
 	if err != nil {
-		if strings.Contains(err.Error(), "Premieres in") {
-			return nil, nil
-		}
 		logError(err, "Get Latest Videos")
 		return nil, err
 	}
-	ids := strings.Split(strings.TrimSpace(out), "\n")
-	videoIds := metaStation.filter(ids)
-	return videoIds, nil
+	var sources []VideoSource
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 || len(fields[1]) == 0 {
+			continue
+		}
+		source := VideoSource{
+			Extractor: fields[0],
+			ID:        fields[1],
+			Url:       fields[2],
+		}
+		if source.isYoutube() {
+			source.Url = "https://www.youtube.com/watch?v=" + source.ID
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
 }
 
-func getVideoId(ctx context.Context, link string) (string, error) {
-	out, err := run(
-		ctx,
-		"yt-dlp",
-		"--quiet",
-		"--print",
-		"id",
-		link,
-	)
-	if err != nil {
-		logError(err, "Get Video Id")
-		return "", err
+// selectVideos drops the videos the show has or had and those waiting for a retry, and evaluates the others against filter
+func (metaStation *MetaStation) selectVideos(ctx context.Context, latest []VideoSource, channelFeedUrl string, sub *Subscription) ([]VideoSource, error) {
+	var sources []VideoSource
+	for _, source := range latest {
+		guid := source.getGUID()
+		if metaStation.HasItem(guid) || metaStation.isExcluded(guid) || sub.Filter.isRejected(guid) || !metaStation.isRetryDue(guid) {
+			continue
+		}
+		var info VideoInfo
+		err := Jobs.do(ctx, METADATA, source.Url, func() (err error) {
+			info, err = getVideoInfo(ctx, source.Url)
+			return err
+		})
+		if errors.Is(err, errUpcoming) {
+			continue
+		} else if ctx.Err() != nil {
+			return nil, err
+		} else if err != nil {
+			// one broken video must not hold up the rest of the channel
+			metaStation.recordFailure(source, channelFeedUrl, err)
+			continue
+		}
+		if reason := sub.Filter.check(info); reason != "" {
+			metaStation.updateSubscription(sub, func(sub *Subscription) {
+				sub.Filter.reject(guid, reason)
+			})
+			continue
+		}
+		sources = append(sources, source)
 	}
-	return strings.TrimSpace(out), nil
+	return sources, nil
 }
 
 func getVideoUsername(ctx context.Context, link string) (string, error) {
-	out, err := run(
+	out, err := ytdlp(
 		ctx,
-		"yt-dlp",
 		"--quiet",
 		"--print",
 		"uploader_id",
@@ -271,9 +506,8 @@ func getVideoUsername(ctx context.Context, link string) (string, error) {
 }
 
 func getVideoTitle(ctx context.Context, link string) (string, error) {
-	out, err := run(
+	out, err := ytdlp(
 		ctx,
-		"yt-dlp",
 		"--quiet",
 		"--print",
 		"title",
@@ -287,9 +521,8 @@ func getVideoTitle(ctx context.Context, link string) (string, error) {
 }
 
 func getVideoDescription(ctx context.Context, link string) (string, error) {
-	out, err := run(
+	out, err := ytdlp(
 		ctx,
-		"yt-dlp",
 		"--quiet",
 		"--print",
 		"description",
@@ -303,9 +536,8 @@ func getVideoDescription(ctx context.Context, link string) (string, error) {
 }
 
 func getVideoDuration(ctx context.Context, link string) (string, error) {
-	out, err := run(
+	out, err := ytdlp(
 		ctx,
-		"yt-dlp",
 		"--quiet",
 		"--print",
 		"duration_string",
@@ -318,10 +550,9 @@ func getVideoDuration(ctx context.Context, link string) (string, error) {
 	return strings.TrimSpace(out), nil
 }
 
-func isValidForDownload(ctx context.Context, link string) error {
-	out, err := run(
+func isValidForDownload(ctx context.Context, link string) (float64, error) {
+	out, err := ytdlp(
 		ctx,
-		"yt-dlp",
 		"--quiet",
 		"--print",
 		"duration",
@@ -329,27 +560,25 @@ func isValidForDownload(ctx context.Context, link string) error {
 	)
 	if err != nil {
 		logError(err, "Is Valid for Download")
-		return err
+		return 0, err
 	}
 
-	durationSeconds, err := strconv.Atoi(strings.TrimSpace(out))
+	durationSeconds, err := strconv.ParseFloat(strings.TrimSpace(out), 64)
 	if err != nil {
 		logError(err, "Is Valid for Download - duration Seconds")
-		return err
+		return 0, err
 	}
-	fiveHoursInSeconds := 5 * 60 * 60
-	if durationSeconds > fiveHoursInSeconds {
-		err = errors.New("video needs to be shorter than 5 hours long!")
-		logError(err, "Is Valid for Download - fiveHoursInSeconds")
-		return err
+	if MaximumDuration > 0 && durationSeconds > MaximumDuration.Seconds() {
+		err = fmt.Errorf("video needs to be shorter than %v: %w", MaximumDuration, errTooLong)
+		logError(err, "Is Valid for Download - MaximumDuration")
+		return 0, err
 	}
-	return nil
+	return durationSeconds, nil
 }
 
 func getVideoViews(ctx context.Context, link string) (uint32, error) {
-	out, err := run(
+	out, err := ytdlp(
 		ctx,
-		"yt-dlp",
 		"--quiet",
 		"--print",
 		"view_count",
@@ -367,9 +596,8 @@ func getVideoViews(ctx context.Context, link string) (uint32, error) {
 }
 
 func getVideoPubDate(ctx context.Context, link string) (string, error) {
-	out, err := run(
+	out, err := ytdlp(
 		ctx,
-		"yt-dlp",
 		"--quiet",
 		"--print",
 		"upload_date",
@@ -383,39 +611,36 @@ func getVideoPubDate(ctx context.Context, link string) (string, error) {
 	return formatDate(out)
 }
 
+// saveVideoThumbnail downloads the highest resolution thumbnail of the video, whatever its format, and converts it for podcast apps
 func (metaStationItem *MetaStationItem) saveVideoThumbnail(ctx context.Context, title string, link string) error {
 	if err := os.MkdirAll(THUMBNAIL_BASE, 0o755); err != nil {
 		return err
 	}
 	localpath2 := Megh.getLocalThumbnailFilepath(metaStationItem.GUID, title)
-	localpath1 := strings.Split(localpath2, ".")[0] + ".webp"
-	_, err := run(
-		ctx,
-		"yt-dlp",
-		"--quiet",
-		"--skip-download",
-		"--write-thumbnail",
-		"-o",
-		strings.Split(localpath2, ".")[0]+".%(ext)s",
-		link,
-	)
-	ConvertImageToCorrectFormat(localpath1, localpath2)
-	return err
+	thumbnailUrl, err := getBestThumbnailUrl(ctx, link)
+	if err != nil {
+		return err
+	}
+	localpath1, err := downloadImage(ctx, thumbnailUrl, strings.TrimSuffix(localpath2, filepath.Ext(localpath2))+".source")
+	if err != nil {
+		return err
+	}
+	return ConvertImageToCorrectFormat(localpath1, localpath2)
 }
 
-func (metaStationItem *MetaStationItem) saveAudio(ctx context.Context, title string, link string, audioQuality int) (uint64, error) {
+func (metaStationItem *MetaStationItem) saveAudio(ctx context.Context, title string, link string, audioQuality string) (uint64, error) {
 	if err := os.MkdirAll(AUDIO_BASE, 0o755); err != nil {
 		return 0, err
 	}
 	localpath := Megh.getLocalAudioFilepath(metaStationItem.GUID, title)
-	_, err := run(ctx,
-		"yt-dlp",
+	_, err := ytdlp(
+		ctx,
 		"--quiet",
 		"-x",
 		"--audio-format",
 		"mp3",
 		"--audio-quality",
-		strconv.Itoa(audioQuality),
+		audioQuality,
 		"-o",
 		strings.Split(localpath, ".")[0]+".%(ext)s",
 		link,
@@ -436,22 +661,39 @@ func formatDate(uploadDate string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return t.Format("Mon, 02 Jan 2006 15:04:05 GMT"), nil
+	return t.Format(PUB_DATE_FORMAT), nil
 }
 
 func (metaStation *MetaStation) updateFeed() (string, error) {
 	station := metaStation.getStation()
 	metaStation.saveMetaStationToLocal()
-	return station.saveFeed()
+	feedUrl, err := station.saveFeed()
+	if err != nil {
+		return "", err
+	}
+	if err := metaStation.saveFeedPages(); err != nil {
+		logError(err, "Update Feed - Archive Pages")
+	}
+	if err := metaStation.saveAlternateFeeds(); err != nil {
+		logError(err, "Update Feed - Atom and JSON Feed")
+	}
+	return feedUrl, nil
 }
 
+// getStation returns the main feed, archived episodes are linked with prev-archive
 func (metaStation *MetaStation) getStation() Station {
-	return Station{
+	items, pages := metaStation.getFeedItems()
+	station := Station{
 		ID:               metaStation.ID,
 		Title:            metaStation.Title,
 		Description:      metaStation.Description,
-		Items:            getStationItems(metaStation.Items),
-		Language:         metaStation.Language,
+		Link:             metaStation.getLink(),
+		LastBuildDate:    metaStation.getLastBuildDate(items),
+		PubDate:          getLatestPubDate(items),
+		Generator:        GENERATOR,
+		TTL:              metaStation.getTTL(),
+		Items:            getStationItems(items),
+		Language:         getLanguageCode(metaStation.Language),
 		Copyright:        metaStation.Copyright,
 		ITunesAuthor:     metaStation.ITunesAuthor,
 		ITunesSubtitle:   metaStation.ITunesSummary,
@@ -459,8 +701,24 @@ func (metaStation *MetaStation) getStation() Station {
 		ITunesImage:      metaStation.ITunesImage,
 		ITunesExplicit:   metaStation.ITunesExplicit,
 		ITunesCategories: metaStation.ITunesCategories,
-		Owner:            metaStation.Owner,
-	}
+		Owner:            metaStation.getOwner(),
+		ITunesType:       metaStation.getITunesType(),
+		PodcastGUID:      metaStation.getPodcastGUID(),
+		PodcastLocked:    metaStation.getPodcastLocked(),
+		PodcastFunding:   metaStation.PodcastFunding,
+		PodcastPersons:   metaStation.getPodcastPersons(),
+		AtomLinks: []AtomLink{
+			{Rel: "self", Type: "application/rss+xml", Href: Megh.getShareableFeedUrl(metaStation.Title)},
+		},
+	}
+	if len(pages) > 0 {
+		station.AtomLinks = append(station.AtomLinks, AtomLink{
+			Rel:  "prev-archive",
+			Type: "application/rss+xml",
+			Href: Megh.getShareableFeedPageUrl(metaStation.Title, len(pages)),
+		})
+	}
+	return station
 }
 
 func GetChannelFeedUrl(username string) (string, error) {
@@ -478,7 +736,7 @@ func GetChannelFeedUrl(username string) (string, error) {
 }
 
 func isValidUrl(url string) bool {
-	resp, err := http.Get(url)
+	resp, err := Network.getHttpClient(15 * time.Second).Get(url)
 	if err != nil {
 		return false
 	}
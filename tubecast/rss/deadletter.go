@@ -0,0 +1,144 @@
+package rss
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// error classes of failed ingest jobs
+const (
+	ERR_GEO_BLOCKED    = "geo_blocked"
+	ERR_MEMBERS_ONLY   = "members_only"
+	ERR_AGE_RESTRICTED = "age_restricted"
+	ERR_PRIVATE        = "private"
+	ERR_UNAVAILABLE    = "unavailable"
+	ERR_TOO_LONG       = "too_long"
+	ERR_TIMEOUT        = "timeout"
+	ERR_UPLOAD         = "upload"
+	ERR_EXTRACTOR      = "extractor"
+	ERR_UNKNOWN        = "unknown"
+)
+
+// longest wait between two automatic retries
+const MAX_RETRY_BACKOFF = 7 * 24 * time.Hour
+
+// classes that fail the same way on every retry, their jobs go to the dead-letter list right away
+var PERMANENT_ERROR_CLASSES = []string{ERR_TOO_LONG, ERR_PRIVATE, ERR_UNAVAILABLE}
+
+var errTooLong = errors.New("longer than MAX_DURATION")
+
+var errorClasses = []struct {
+	class    string
+	patterns []string
+}{
+	{ERR_GEO_BLOCKED, []string{"available in your country", "geo restriction", "geo-restricted"}},
+	{ERR_MEMBERS_ONLY, []string{"members-only", "members only", "join this channel"}},
+	{ERR_AGE_RESTRICTED, []string{"confirm your age", "age-restricted", "inappropriate for some users"}},
+	{ERR_PRIVATE, []string{"private video"}},
+	{ERR_UNAVAILABLE, []string{"video unavailable", "has been removed", "does not exist"}},
+	{ERR_UPLOAD, []string{"could not upload"}},
+	{ERR_EXTRACTOR, []string{"error:", "unsupported url", "unable to extract"}},
+}
+
+func classifyError(err error) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ERR_TIMEOUT
+	}
+	if errors.Is(err, errTooLong) {
+		return ERR_TOO_LONG
+	}
+	msg := strings.ToLower(err.Error())
+	for _, c := range errorClasses {
+		for _, pattern := range c.patterns {
+			if strings.Contains(msg, pattern) {
+				return c.class
+			}
+		}
+	}
+	return ERR_UNKNOWN
+}
+
+// getRetryBackoff doubles the wait after every failed attempt
+func getRetryBackoff(attempts int) time.Duration {
+	backoff := RetryBackoff
+	for i := 1; i < attempts && backoff < MAX_RETRY_BACKOFF; i++ {
+		backoff *= 2
+	}
+	return min(backoff, MAX_RETRY_BACKOFF)
+}
+
+// isRetryDue reports whether Sync may try the video again: it has no failed job,
+// or its backoff has elapsed and it has not been moved to the dead-letter list
+func (metaStation *MetaStation) isRetryDue(guid string) bool {
+	job, err := loadIngestJob(getJobFilepath(metaStation.Title, guid))
+	if err != nil {
+		return true
+	}
+	switch job.State {
+	case JOB_DEAD:
+		return false
+	case JOB_FAILED:
+		return !time.Now().Before(job.NextRetry)
+	}
+	return true
+}
+
+// isSettled reports whether the video has no ingest job waiting for a retry: it was added,
+// skipped, or moved to the dead-letter list
+func (metaStation *MetaStation) isSettled(guid string) bool {
+	job, err := loadIngestJob(getJobFilepath(metaStation.Title, guid))
+	return err != nil || job.State == JOB_DEAD
+}
+
+// recordFailure books a failure of a video that did not get as far as an ingest job
+func (metaStation *MetaStation) recordFailure(source VideoSource, channelFeedUrl string, err error) {
+	job := getIngestJob(metaStation.Title, source, "", channelFeedUrl)
+	job.Attempts++
+	job.fail(err)
+}
+
+func (metaStation *MetaStation) getFailedItems() []FailedInfo {
+	var out []FailedInfo
+	for _, job := range loadIngestJobs() {
+		if job.Station != metaStation.Title || (job.State != JOB_FAILED && job.State != JOB_DEAD) {
+			continue
+		}
+		title := job.Item.Title
+		if len(title) == 0 {
+			title = job.Source.Url
+		}
+		out = append(out, FailedInfo{
+			GUID:       job.Item.GUID,
+			Title:      title,
+			Link:       job.Source.Url,
+			ErrorClass: job.ErrorClass,
+			Error:      job.Error,
+			Attempts:   job.Attempts,
+			NextRetry:  job.NextRetry,
+			Dead:       job.State == JOB_DEAD,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Dead != out[j].Dead {
+			return out[i].Dead
+		}
+		return out[i].NextRetry.Before(out[j].NextRetry)
+	})
+	return out
+}
+
+// retryFailedItem runs a failed or dead job right away with a fresh attempt count
+func (metaStation *MetaStation) retryFailedItem(guid string) (string, error) {
+	job, err := loadIngestJob(getJobFilepath(metaStation.Title, guid))
+	if err != nil || (job.State != JOB_FAILED && job.State != JOB_DEAD) {
+		return "", errors.New("video has not failed in this show")
+	}
+	job.Attempts = 0
+	job.setState(JOB_QUEUED)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+	return metaStation.runIngestJob(ctx, job)
+}
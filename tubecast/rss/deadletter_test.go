@@ -0,0 +1,53 @@
+package rss
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestClassifyError(t *testing.T) {
+	for _, test := range []struct {
+		err  error
+		want string
+	}{
+		{context.DeadlineExceeded, ERR_TIMEOUT},
+		{fmt.Errorf("could not download: %w", context.DeadlineExceeded), ERR_TIMEOUT},
+		{fmt.Errorf("video must be shorter than 3h0m0s: %w", errTooLong), ERR_TOO_LONG},
+		{errors.New("the maximum duration of the show"), ERR_UNKNOWN},
+		{errors.New("ERROR: [youtube] abc: The uploader has not made this video available in your country"), ERR_GEO_BLOCKED},
+		{errors.New("ERROR: [youtube] abc: Join this channel to get access to members-only content"), ERR_MEMBERS_ONLY},
+		{errors.New("ERROR: [youtube] abc: Sign in to confirm your age"), ERR_AGE_RESTRICTED},
+		{errors.New("ERROR: [youtube] abc: Private video. Sign in if you've been granted access"), ERR_PRIVATE},
+		{errors.New("ERROR: [youtube] abc: Video unavailable"), ERR_UNAVAILABLE},
+		{errors.New("could not upload audio: 503"), ERR_UPLOAD},
+		{errors.New("ERROR: Unsupported URL: https://example.com"), ERR_EXTRACTOR},
+		{errors.New("exit status 1"), ERR_UNKNOWN},
+	} {
+		if got := classifyError(test.err); got != test.want {
+			t.Errorf("classifyError(%q) = %s, want %s", test.err, got, test.want)
+		}
+	}
+}
+
+func TestGetRetryBackoff(t *testing.T) {
+	backoff := RetryBackoff
+	RetryBackoff = time.Hour
+	t.Cleanup(func() { RetryBackoff = backoff })
+
+	for attempts, want := range map[int]time.Duration{
+		0:  time.Hour,
+		1:  time.Hour,
+		2:  2 * time.Hour,
+		4:  8 * time.Hour,
+		8:  128 * time.Hour,
+		9:  MAX_RETRY_BACKOFF,
+		50: MAX_RETRY_BACKOFF,
+	} {
+		if got := getRetryBackoff(attempts); got != want {
+			t.Errorf("getRetryBackoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
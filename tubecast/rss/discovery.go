@@ -0,0 +1,91 @@
+package rss
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type atomValidators struct {
+	ETag         string
+	LastModified string
+}
+
+type atomFeed struct {
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	VideoID   string `xml:"http://www.youtube.com/xml/schemas/2015 videoId"`
+	Title     string `xml:"title"`
+	Published string `xml:"published"`
+}
+
+func (sub *Subscription) supportsAtomDiscovery() bool {
+	return (sub.Kind == CHANNEL_VIDEOS && isChannelId(sub.ID)) || sub.Kind == PLAYLIST
+}
+
+func (sub *Subscription) getAtomFeedUrl() string {
+	query := url.Values{}
+	if sub.Kind == PLAYLIST {
+		query.Set("playlist_id", sub.ID)
+	} else {
+		query.Set("channel_id", sub.ID)
+	}
+	return YOUTUBE_FEED_BASE + "?" + query.Encode()
+}
+
+// discoverLatestVideos polls the YouTube Atom feed of the subscription. It returns
+// no videos when the feed has not changed since the previous poll. The caller
+// stores the returned cache validators once the videos have been handled.
+func (sub *Subscription) discoverLatestVideos(ctx context.Context, limit uint) ([]VideoSource, atomValidators, error) {
+	validators := atomValidators{ETag: sub.ETag, LastModified: sub.LastModified}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sub.getAtomFeedUrl(), nil)
+	if err != nil {
+		return nil, validators, err
+	}
+	if len(sub.ETag) > 0 {
+		req.Header.Set("If-None-Match", sub.ETag)
+	}
+	if len(sub.LastModified) > 0 {
+		req.Header.Set("If-Modified-Since", sub.LastModified)
+	}
+	resp, err := Network.getHttpClient(15 * time.Second).Do(req)
+	if err != nil {
+		return nil, validators, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, validators, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, validators, fmt.Errorf("atom feed returned %s", resp.Status)
+	}
+	var feed atomFeed
+	if err := xml.NewDecoder(resp.Body).Decode(&feed); err != nil {
+		return nil, validators, err
+	}
+	validators = atomValidators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+
+	var sources []VideoSource
+	for _, entry := range feed.Entries {
+		if uint(len(sources)) == limit {
+			break
+		}
+		if len(entry.VideoID) == 0 {
+			continue
+		}
+		sources = append(sources, VideoSource{
+			Extractor: YOUTUBE_EXTRACTOR,
+			ID:        entry.VideoID,
+			Url:       "https://www.youtube.com/watch?v=" + entry.VideoID,
+		})
+	}
+	return sources, validators, nil
+}
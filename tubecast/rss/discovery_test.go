@@ -0,0 +1,102 @@
+package rss
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const atomFixture = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns="http://www.w3.org/2005/Atom">
+  <entry><yt:videoId>video1</yt:videoId><title>First</title></entry>
+  <entry><title>Not a video</title></entry>
+  <entry><yt:videoId>video2</yt:videoId><title>Second</title></entry>
+  <entry><yt:videoId>video3</yt:videoId><title>Third</title></entry>
+</feed>`
+
+const testChannelId = "UCxxxxxxxxxxxxxxxxxxxxxx"
+
+// serveAtomFeed points YOUTUBE_FEED_BASE at a local server for the duration of the test
+func serveAtomFeed(t *testing.T, handler http.HandlerFunc) {
+	server := httptest.NewServer(handler)
+	base := YOUTUBE_FEED_BASE
+	YOUTUBE_FEED_BASE = server.URL + "/feeds/videos.xml"
+	t.Cleanup(func() {
+		YOUTUBE_FEED_BASE = base
+		server.Close()
+	})
+}
+
+func TestGetAtomFeedUrl(t *testing.T) {
+	channel := Subscription{Kind: CHANNEL_VIDEOS, ID: testChannelId}
+	if got := channel.getAtomFeedUrl(); got != YOUTUBE_FEED_BASE+"?channel_id="+testChannelId {
+		t.Errorf("channel feed = %q", got)
+	}
+	playlist := Subscription{Kind: PLAYLIST, ID: "PLabc"}
+	if got := playlist.getAtomFeedUrl(); got != YOUTUBE_FEED_BASE+"?playlist_id=PLabc" {
+		t.Errorf("playlist feed = %q", got)
+	}
+	if (&Subscription{Kind: CHANNEL_VIDEOS, ID: "@handle"}).supportsAtomDiscovery() {
+		t.Error("a handle has no Atom feed, it needs to be resolved first")
+	}
+}
+
+func TestDiscoverLatestVideos(t *testing.T) {
+	const etag = `"feed-v1"`
+	serveAtomFeed(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("channel_id") != testChannelId {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(atomFixture))
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	sub := Subscription{Kind: CHANNEL_VIDEOS, ID: testChannelId}
+	sources, validators, err := sub.discoverLatestVideos(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sources) != 2 || sources[0].ID != "video1" || sources[1].ID != "video2" {
+		t.Fatalf("sources = %+v, want video1 and video2", sources)
+	}
+	if sources[0].Url != "https://www.youtube.com/watch?v=video1" || sources[0].Extractor != YOUTUBE_EXTRACTOR {
+		t.Errorf("source = %+v", sources[0])
+	}
+	if validators.ETag != etag {
+		t.Errorf("ETag = %q, want %q", validators.ETag, etag)
+	}
+
+	sub.ETag = validators.ETag
+	sources, unchanged, err := sub.discoverLatestVideos(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sources) != 0 || unchanged != validators {
+		t.Errorf("a 304 returned %+v with validators %+v", sources, unchanged)
+	}
+}
+
+func TestDiscoverLatestVideosError(t *testing.T) {
+	serveAtomFeed(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<feed"))
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	sub := Subscription{Kind: CHANNEL_VIDEOS, ID: testChannelId, ETag: `"old"`}
+	_, validators, err := sub.discoverLatestVideos(ctx, 3)
+	if err == nil {
+		t.Fatal("expected an error for a malformed feed")
+	}
+	if validators.ETag != `"old"` {
+		t.Errorf("a failed poll replaced the ETag with %q", validators.ETag)
+	}
+}
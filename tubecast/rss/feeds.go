@@ -0,0 +1,271 @@
+package rss
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ATOM_NAMESPACE    = "http://www.w3.org/2005/Atom"
+	JSON_FEED_VERSION = "https://jsonfeed.org/version/1.1"
+)
+
+type AtomFeed struct {
+	XMLName  xml.Name    `xml:"feed"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Author   AtomPerson  `xml:"author"`
+	Links    []AtomLink  `xml:"link"`
+	Icon     string      `xml:"icon,omitempty"`
+	Logo     string      `xml:"logo,omitempty"`
+	Rights   string      `xml:"rights,omitempty"`
+	Entries  []AtomEntry `xml:"entry"`
+}
+
+type AtomPerson struct {
+	Name  string `xml:"name"`
+	Email string `xml:"email,omitempty"`
+	Uri   string `xml:"uri,omitempty"`
+}
+
+type AtomLink struct {
+	Rel    string `xml:"rel,attr,omitempty"`
+	Type   string `xml:"type,attr,omitempty"`
+	Href   string `xml:"href,attr"`
+	Length uint64 `xml:"length,attr,omitempty"`
+	Title  string `xml:"title,attr,omitempty"`
+}
+
+type AtomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Updated   string     `xml:"updated"`
+	Published string     `xml:"published,omitempty"`
+	Author    AtomPerson `xml:"author"`
+	Links     []AtomLink `xml:"link"`
+	Summary   AtomText   `xml:"summary"`
+	Content   *AtomText  `xml:"content,omitempty"`
+}
+
+type AtomText struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+type JsonFeed struct {
+	Version     string           `json:"version"`
+	Title       string           `json:"title"`
+	HomePageUrl string           `json:"home_page_url,omitempty"`
+	FeedUrl     string           `json:"feed_url"`
+	Description string           `json:"description,omitempty"`
+	Icon        string           `json:"icon,omitempty"`
+	Favicon     string           `json:"favicon,omitempty"`
+	Authors     []JsonFeedAuthor `json:"authors,omitempty"`
+	Language    string           `json:"language,omitempty"`
+	Items       []JsonFeedItem   `json:"items"`
+}
+
+type JsonFeedAuthor struct {
+	Name string `json:"name"`
+	Url  string `json:"url,omitempty"`
+}
+
+type JsonFeedItem struct {
+	ID            string               `json:"id"`
+	Url           string               `json:"url,omitempty"`
+	Title         string               `json:"title"`
+	ContentHtml   string               `json:"content_html,omitempty"`
+	ContentText   string               `json:"content_text"`
+	Summary       string               `json:"summary,omitempty"`
+	Image         string               `json:"image,omitempty"`
+	DatePublished string               `json:"date_published,omitempty"`
+	Authors       []JsonFeedAuthor     `json:"authors,omitempty"`
+	Attachments   []JsonFeedAttachment `json:"attachments"`
+}
+
+type JsonFeedAttachment struct {
+	Url               string  `json:"url"`
+	MimeType          string  `json:"mime_type"`
+	SizeInBytes       uint64  `json:"size_in_bytes,omitempty"`
+	DurationInSeconds float64 `json:"duration_in_seconds,omitempty"`
+}
+
+// getPublishedItems returns the episodes newest first
+func (metaStation *MetaStation) getPublishedItems() []MetaStationItem {
+	items := append([]MetaStationItem(nil), metaStation.Items...)
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].getPublished().After(items[j].getPublished())
+	})
+	return items
+}
+
+// getFeedUUID returns the podcast:guid of the show as a UUID, or one derived from it when a stored guid is not a UUID
+func (metaStation *MetaStation) getFeedUUID() uuid.UUID {
+	guid := metaStation.getPodcastGUID()
+	if id, err := uuid.Parse(guid); err == nil {
+		return id
+	}
+	return uuid.NewSHA1(PODCAST_GUID_NAMESPACE, []byte(guid))
+}
+
+// getEntryID returns a stable urn:uuid for an episode of the show
+func (metaStation *MetaStation) getEntryID(guid string) string {
+	return "urn:uuid:" + uuid.NewSHA1(metaStation.getFeedUUID(), []byte(guid)).String()
+}
+
+func (metaStation *MetaStation) getAtomFeed() AtomFeed {
+	items, _ := metaStation.getFeedItems()
+	updated := metaStation.CreatedOn.UTC()
+	if len(items) > 0 {
+		updated = items[0].getPublished()
+	}
+	feed := AtomFeed{
+		Xmlns:    ATOM_NAMESPACE,
+		ID:       "urn:uuid:" + metaStation.getFeedUUID().String(),
+		Title:    metaStation.Title,
+		Subtitle: metaStation.Description,
+		Updated:  updated.Format(time.RFC3339),
+		Author:   AtomPerson{Name: metaStation.ITunesAuthor, Email: metaStation.Owner.Email},
+		Links: []AtomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: Megh.getShareableAtomFeedUrl(metaStation.Title)},
+			{Rel: "alternate", Type: "application/rss+xml", Href: Megh.getShareableFeedUrl(metaStation.Title)},
+		},
+		Icon:   metaStation.ITunesImage.Href,
+		Logo:   metaStation.ITunesImage.Href,
+		Rights: metaStation.Copyright,
+	}
+	for _, item := range items {
+		notes := item.getShowNotes()
+		published := item.getPublished().Format(time.RFC3339)
+		entry := AtomEntry{
+			ID:        metaStation.getEntryID(item.GUID),
+			Title:     item.Title,
+			Updated:   published,
+			Published: published,
+			Author:    AtomPerson{Name: item.ITunesAuthor},
+			Summary:   AtomText{Type: "text", Text: notes.Description},
+			Content:   &AtomText{Type: "html", Text: notes.Html},
+		}
+		if len(entry.Author.Name) == 0 {
+			entry.Author.Name = metaStation.ITunesAuthor
+		}
+		if len(item.Link) > 0 {
+			entry.Links = append(entry.Links, AtomLink{Rel: "alternate", Type: "text/html", Href: item.Link})
+		}
+		entry.Links = append(entry.Links, AtomLink{
+			Rel:    "enclosure",
+			Type:   item.Enclosure.Type,
+			Href:   item.Enclosure.URL,
+			Length: item.Enclosure.Length,
+			Title:  item.Title,
+		})
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return feed
+}
+
+func (metaStation *MetaStation) getJsonFeed() JsonFeed {
+	items, _ := metaStation.getFeedItems()
+	feed := JsonFeed{
+		Version:     JSON_FEED_VERSION,
+		Title:       metaStation.Title,
+		FeedUrl:     Megh.getShareableJsonFeedUrl(metaStation.Title),
+		Description: metaStation.Description,
+		Icon:        metaStation.ITunesImage.Href,
+		Favicon:     metaStation.ITunesImage.Href,
+		Language:    getLanguageCode(metaStation.Language),
+		Items:       []JsonFeedItem{},
+	}
+	if len(metaStation.ITunesAuthor) > 0 {
+		feed.Authors = []JsonFeedAuthor{{Name: metaStation.ITunesAuthor}}
+	}
+	for _, item := range items {
+		notes := item.getShowNotes()
+		feedItem := JsonFeedItem{
+			ID:            metaStation.getEntryID(item.GUID),
+			Url:           item.Link,
+			Title:         item.Title,
+			ContentHtml:   notes.Html,
+			ContentText:   notes.Description,
+			Summary:       item.ITunesSummary,
+			Image:         item.ITunesImage.Href,
+			DatePublished: item.getPublished().Format(time.RFC3339),
+			Attachments: []JsonFeedAttachment{{
+				Url:               item.Enclosure.URL,
+				MimeType:          item.Enclosure.Type,
+				SizeInBytes:       item.Enclosure.Length,
+				DurationInSeconds: parseITunesDuration(item.ITunesDuration),
+			}},
+		}
+		if len(item.ITunesAuthor) > 0 {
+			author := JsonFeedAuthor{Name: item.ITunesAuthor}
+			if isHttpUrl(item.ChannelID) {
+				author.Url = item.ChannelID
+			}
+			feedItem.Authors = []JsonFeedAuthor{author}
+		}
+		feed.Items = append(feed.Items, feedItem)
+	}
+	return feed
+}
+
+// saveAlternateFeeds writes the Atom and JSON Feed versions of the show and uploads them next to the RSS.
+// A version is uploaded only when its content changed.
+func (metaStation *MetaStation) saveAlternateFeeds() error {
+	atom, err := xml.MarshalIndent(metaStation.getAtomFeed(), "", "  ")
+	if err != nil {
+		return err
+	}
+	jsonFeed, err := json.MarshalIndent(metaStation.getJsonFeed(), "", "  ")
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
+	if err := saveAlternateFeed(ctx, metaStation.Title, ATOM_FEED, Megh.getLocalAtomFeedFilepath(metaStation.Title), append([]byte(xml.Header), atom...)); err != nil {
+		return err
+	}
+	return saveAlternateFeed(ctx, metaStation.Title, JSON_FEED, Megh.getLocalJsonFeedFilepath(metaStation.Title), jsonFeed)
+}
+
+func saveAlternateFeed(ctx context.Context, title string, fileType FileType, path string, data []byte) error {
+	if old, err := os.ReadFile(path); err == nil && bytes.Equal(old, data) {
+		return nil
+	}
+	if err := writeFeedFile(path, data); err != nil {
+		return err
+	}
+	if !Megh.IsArchive {
+		return nil
+	}
+	if _, err := Megh.upload(ctx, "", title, fileType); err != nil {
+		// the next update uploads it again
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
+
+// writeFeedFile atomically replaces a feed file
+func writeFeedFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,55 @@
+package rss
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestGetEntryID(t *testing.T) {
+	metaStation := MetaStation{PodcastGUID: "917393e3-1b1e-5cef-ace4-edaa54e1f810"}
+	id := metaStation.getEntryID("video1")
+	if !strings.HasPrefix(id, "urn:uuid:") || id != metaStation.getEntryID("video1") {
+		t.Fatalf("entry ID %q is not a stable urn:uuid", id)
+	}
+	if id == metaStation.getEntryID("video2") {
+		t.Error("two episodes share an entry ID")
+	}
+
+	// a stored guid that is not a UUID must not panic
+	legacy := MetaStation{PodcastGUID: "not-a-uuid"}
+	if _, err := uuid.Parse(strings.TrimPrefix(legacy.getEntryID("video1"), "urn:uuid:")); err != nil {
+		t.Errorf("entry ID of a show with a non-UUID guid: %v", err)
+	}
+	if feedID := legacy.getAtomFeed().ID; feedID == "urn:uuid:not-a-uuid" {
+		t.Errorf("Atom feed ID = %q, want a UUID", feedID)
+	}
+}
+
+func TestAlternateFeedsFollowFeedItemLimit(t *testing.T) {
+	metaStation := MetaStation{
+		Title:         "Fixture",
+		Language:      "English",
+		FeedItemLimit: 2,
+		Items: []MetaStationItem{
+			{GUID: "a", Title: "A", PubDate: "Sat, 01 Jan 2022 10:00:00 GMT"},
+			{GUID: "c", Title: "C", PubDate: "Mon, 03 Jan 2022 10:00:00 GMT"},
+			{GUID: "b", Title: "B", PubDate: "Sun, 02 Jan 2022 10:00:00 GMT"},
+		},
+	}
+	atom := metaStation.getAtomFeed()
+	if len(atom.Entries) != 2 || atom.Entries[0].Title != "C" || atom.Entries[1].Title != "B" {
+		t.Errorf("Atom entries = %+v, want C and B", atom.Entries)
+	}
+	if atom.Updated != "2022-01-03T10:00:00Z" {
+		t.Errorf("Atom updated = %q", atom.Updated)
+	}
+	jsonFeed := metaStation.getJsonFeed()
+	if len(jsonFeed.Items) != 2 || jsonFeed.Items[0].Title != "C" || jsonFeed.Items[1].Title != "B" {
+		t.Errorf("JSON Feed items = %+v, want C and B", jsonFeed.Items)
+	}
+	if jsonFeed.Language != "en" {
+		t.Errorf("JSON Feed language = %q, want en", jsonFeed.Language)
+	}
+}
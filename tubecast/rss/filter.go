@@ -0,0 +1,129 @@
+package rss
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Shorts are vertical and at most three minutes long
+const SHORTS_MAX_DURATION = 3 * 60
+
+var errUpcoming = errors.New("video has not premiered yet")
+
+// validate checks the rules and compiles the patterns for check
+func (filter *SubscriptionFilter) validate() error {
+	if filter.MaxDuration > 0 && filter.MinDuration > filter.MaxDuration {
+		return fmt.Errorf("minimum duration %ds is longer than maximum duration %ds", filter.MinDuration, filter.MaxDuration)
+	}
+	filter.include, filter.exclude = nil, nil
+	if filter.IncludePattern != "" {
+		re, err := regexp.Compile(filter.IncludePattern)
+		if err != nil {
+			return fmt.Errorf("invalid include pattern: %w", err)
+		}
+		filter.include = re
+	}
+	if filter.ExcludePattern != "" {
+		re, err := regexp.Compile(filter.ExcludePattern)
+		if err != nil {
+			return fmt.Errorf("invalid exclude pattern: %w", err)
+		}
+		filter.exclude = re
+	}
+	return nil
+}
+
+// hasSameRules reports whether other rejects the same videos, so its rejections still hold
+func (filter *SubscriptionFilter) hasSameRules(other SubscriptionFilter) bool {
+	return filter.MinDuration == other.MinDuration &&
+		filter.MaxDuration == other.MaxDuration &&
+		filter.IncludePattern == other.IncludePattern &&
+		filter.ExcludePattern == other.ExcludePattern &&
+		filter.SkipShorts == other.SkipShorts &&
+		filter.SkipLive == other.SkipLive &&
+		filter.SkipMembersOnly == other.SkipMembersOnly
+}
+
+// check returns the reason the video is rejected, or "" when it should be added
+func (filter *SubscriptionFilter) check(info VideoInfo) string {
+	if filter.MinDuration > 0 && info.Duration < float64(filter.MinDuration) {
+		return fmt.Sprintf("shorter than %ds", filter.MinDuration)
+	}
+	if filter.MaxDuration > 0 && info.Duration > float64(filter.MaxDuration) {
+		return fmt.Sprintf("longer than %ds", filter.MaxDuration)
+	}
+	if filter.SkipShorts && isShort(info) {
+		return "short"
+	}
+	if filter.SkipLive && (info.LiveStatus == "was_live" || info.LiveStatus == "is_live") {
+		return "livestream"
+	}
+	if filter.SkipMembersOnly && info.Availability == "subscriber_only" {
+		return "members only"
+	}
+	if (filter.IncludePattern != "" && filter.include == nil) || (filter.ExcludePattern != "" && filter.exclude == nil) {
+		// loaded from the station file, an invalid pattern never got that far.
+		// A pattern that does not compile rejects every video until the filter is fixed.
+		if err := filter.validate(); err != nil {
+			logError(err, "Filter - Check")
+			return "invalid filter"
+		}
+	}
+	text := info.Title + "\n" + info.Description
+	if filter.include != nil && !filter.include.MatchString(text) {
+		return "does not match include pattern"
+	}
+	if filter.exclude != nil && filter.exclude.MatchString(text) {
+		return "matches exclude pattern"
+	}
+	return ""
+}
+
+func (filter *SubscriptionFilter) reject(id, reason string) {
+	if filter.Rejected == nil {
+		filter.Rejected = make(map[string]string)
+	}
+	filter.Rejected[id] = reason
+}
+
+func (filter *SubscriptionFilter) isRejected(id string) bool {
+	_, ok := filter.Rejected[id]
+	return ok
+}
+
+func isShort(info VideoInfo) bool {
+	if strings.Contains(info.WebpageUrl, "/shorts/") {
+		return true
+	}
+	return info.Duration <= SHORTS_MAX_DURATION && info.Height > info.Width
+}
+
+func getVideoInfo(ctx context.Context, link string) (VideoInfo, error) {
+	out, err := ytdlp(
+		ctx,
+		"--quiet",
+		"--skip-download",
+		"--print",
+		"%(.{id,title,description,duration,live_status,availability,width,height,webpage_url})j",
+		link,
+	)
+	if err != nil {
+		if strings.Contains(err.Error(), "Premieres in") || strings.Contains(err.Error(), "live event will begin") {
+			return VideoInfo{}, errUpcoming
+		}
+		if strings.Contains(err.Error(), "members-only") || strings.Contains(err.Error(), "Join this channel") {
+			return VideoInfo{Availability: "subscriber_only"}, nil
+		}
+		logError(err, "Get Video Info")
+		return VideoInfo{}, err
+	}
+	var info VideoInfo
+	if err := json.Unmarshal([]byte(strings.TrimSpace(out)), &info); err != nil {
+		return VideoInfo{}, err
+	}
+	return info, nil
+}
@@ -0,0 +1,214 @@
+package rss
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+type JobState string
+
+const (
+	JOB_QUEUED      JobState = "queued"
+	JOB_DOWNLOADING JobState = "downloading"
+	JOB_UPLOADING   JobState = "uploading"
+	JOB_COMMITTED   JobState = "committed"
+	JOB_FAILED      JobState = "failed"
+	// failed MaxAttempts times, only retried by hand
+	JOB_DEAD JobState = "dead"
+)
+
+// getIngestJob loads the job of a video that was attempted before, or queues a new one
+func getIngestJob(station string, source VideoSource, author, channelFeedUrl string) *IngestJob {
+	guid := source.getGUID()
+	if job, err := loadIngestJob(getJobFilepath(station, guid)); err == nil {
+		// failures recorded while selecting videos do not know the author yet
+		if len(job.Author) == 0 {
+			job.Author = author
+			job.Item.ITunesAuthor = author
+		}
+		if len(job.ChannelFeedUrl) == 0 {
+			job.ChannelFeedUrl = channelFeedUrl
+			job.Item.ChannelID = channelFeedUrl
+		}
+		return job
+	}
+	job := &IngestJob{
+		Station:        station,
+		Source:         source,
+		Author:         author,
+		ChannelFeedUrl: channelFeedUrl,
+		State:          JOB_QUEUED,
+		CreatedOn:      time.Now(),
+		Item: MetaStationItem{
+			GUID:           guid,
+			ITunesAuthor:   author,
+			ChannelID:      channelFeedUrl,
+			AddedOn:        time.Now(),
+			ITunesExplicit: "no",
+			Link:           source.Url,
+			SourceUrl:      source.Url,
+			Extractor:      source.Extractor,
+		},
+	}
+	job.save()
+	return job
+}
+
+func getJobFilepath(station, guid string) string {
+	return filepath.Join(JOB_BASE, station+"_"+guid+".json")
+}
+
+// isDownloaded reports whether the audio of the job has been downloaded or already uploaded
+func (job *IngestJob) isDownloaded() bool {
+	if len(job.Item.Enclosure.URL) > 0 {
+		return true
+	}
+	if job.AudioSize == 0 {
+		return false
+	}
+	_, err := os.Stat(Megh.getLocalAudioFilepath(job.Item.GUID, job.Station))
+	return err == nil
+}
+
+// isSplit reports whether the video is published in parts
+func (job *IngestJob) isSplit() bool {
+	return SplitDuration > 0 && job.Duration > SplitDuration.Seconds()
+}
+
+var jobLocks sync.Map
+
+var errJobRunning = errors.New("the video is being added already")
+
+// tryLock keeps a resumed job and a sync from working on the same video at once
+func (job *IngestJob) tryLock() (func(), bool) {
+	m, _ := jobLocks.LoadOrStore(getJobFilepath(job.Station, job.Item.GUID), &sync.Mutex{})
+	mu := m.(*sync.Mutex)
+	if !mu.TryLock() {
+		return nil, false
+	}
+	return mu.Unlock, true
+}
+
+func (job *IngestJob) setState(state JobState) {
+	job.State = state
+	job.save()
+}
+
+// fail records the error and schedules the next retry, or moves the job to the dead-letter list
+// once it has failed MaxAttempts times or the error will not go away
+func (job *IngestJob) fail(err error) error {
+	job.Error = err.Error()
+	job.ErrorClass = classifyError(err)
+	if job.Attempts >= MaxAttempts || slices.Contains(PERMANENT_ERROR_CLASSES, job.ErrorClass) {
+		job.NextRetry = time.Time{}
+		job.setState(JOB_DEAD)
+		return err
+	}
+	job.NextRetry = time.Now().Add(getRetryBackoff(job.Attempts))
+	job.setState(JOB_FAILED)
+	return err
+}
+
+// commit removes the job, its episode is part of the station from now on
+func (job *IngestJob) commit() {
+	job.State = JOB_COMMITTED
+	os.Remove(getJobFilepath(job.Station, job.Item.GUID))
+}
+
+// Atomatically save the job
+func (job *IngestJob) save() error {
+	job.UpdatedOn = time.Now()
+	path := getJobFilepath(job.Station, job.Item.GUID)
+	tmp := path + ".tmp"
+	if err := os.MkdirAll(JOB_BASE, 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	defer os.Remove(tmp)
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(job); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func loadIngestJob(path string) (*IngestJob, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var job IngestJob
+	if err := json.NewDecoder(f).Decode(&job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func loadIngestJobs() []*IngestJob {
+	var jobs []*IngestJob
+	entries, err := os.ReadDir(JOB_BASE)
+	if err != nil {
+		return nil
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		job, err := loadIngestJob(filepath.Join(JOB_BASE, e.Name()))
+		if err != nil {
+			logError(err, "Load Ingest Job "+e.Name())
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+// resumeIngestJobs finishes the jobs that were interrupted by a crash or restart.
+// Failed jobs are left for the next sync once their backoff elapsed.
+func resumeIngestJobs() {
+	for _, job := range loadIngestJobs() {
+		switch job.State {
+		case JOB_QUEUED, JOB_DOWNLOADING, JOB_UPLOADING:
+		case JOB_COMMITTED:
+			job.commit()
+			continue
+		default:
+			continue
+		}
+		if !StationNames.Has(job.Station) {
+			if len(job.Item.MediaKey) > 0 {
+				// the show was deleted while the job held a reference
+				metaStation := MetaStation{Title: job.Station}
+				if _, err := metaStation.releaseMedia(context.Background(), job.Item.MediaKey); err != nil {
+					logError(err, "Resume Ingest Job - Release Media")
+				}
+			}
+			job.commit()
+			continue
+		}
+		metaStation, err := getMetaStation(job.Station, "")
+		if err != nil {
+			logError(err, "Resume Ingest Job")
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		if _, err := metaStation.runIngestJob(ctx, job); err != nil {
+			logError(err, "Resume Ingest Job")
+		}
+		cancel()
+	}
+}
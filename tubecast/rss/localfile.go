@@ -0,0 +1,157 @@
+package rss
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// relative paths of local files are resolved against this folder, which is mounted into the Docker container
+var FILE_BASE string = "tubecast/files"
+
+// LOCAL_FILE_EXTRACTOR marks episodes made from a local file instead of a video
+const LOCAL_FILE_EXTRACTOR = "File"
+
+func getLocalFilepath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(FILE_BASE, path)
+}
+
+// addFile publishes a local audio or video file as an episode
+func (metaStation *MetaStation) addFile(path, title, description, artwork string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	src := getLocalFilepath(path)
+	if _, err := os.Stat(src); err != nil {
+		return "", err
+	}
+	metaStationItem := MetaStationItem{
+		GUID:           "file_" + uuid.New().String(),
+		ITunesAuthor:   metaStation.ITunesAuthor,
+		AddedOn:        time.Now(),
+		ITunesExplicit: "no",
+		Title:          title,
+		Description:    description,
+		PubDate:        time.Now().UTC().Format(PUB_DATE_FORMAT),
+		Extractor:      LOCAL_FILE_EXTRACTOR,
+	}
+	duration, err := getMediaDuration(ctx, src)
+	if err != nil {
+		return "", fmt.Errorf("could not read %s: %w", filepath.Base(src), err)
+	}
+	metaStationItem.ITunesDuration = formatDuration(duration)
+
+	var size uint64
+	err = Jobs.do(ctx, DOWNLOAD, FILE_BASE, func() (err error) {
+		size, err = transcodeAudio(ctx, src, Megh.getLocalAudioFilepath(metaStationItem.GUID, metaStation.Title), metaStation.getAudioProfile())
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(artwork) > 0 {
+		if err := metaStationItem.saveArtwork(getLocalFilepath(artwork), metaStation.Title); err != nil {
+			logError(err, "Add File - Artwork")
+		}
+	}
+	if tagged, err := metaStation.tagAudio(ctx, metaStationItem); err != nil {
+		logError(err, "Add File - Tag Audio")
+	} else {
+		size = tagged
+	}
+	key, enclosure, err := metaStation.storeMedia(ctx, metaStationItem.GUID, size, true)
+	if err != nil {
+		return "", err
+	}
+	metaStationItem.MediaKey = key
+	metaStationItem.Enclosure = enclosure
+	metaStationItem.ITunesImage = metaStation.uploadThumbnail(ctx, metaStationItem.GUID)
+
+	defer metaStation.lock()()
+	metaStation.addToStation(metaStationItem)
+	return metaStation.updateFeed()
+}
+
+// saveArtwork converts a copy of the artwork into the episode thumbnail, the original is left alone
+func (metaStationItem *MetaStationItem) saveArtwork(artwork, title string) error {
+	if err := os.MkdirAll(THUMBNAIL_BASE, 0o755); err != nil {
+		return err
+	}
+	thumbnail := Megh.getLocalThumbnailFilepath(metaStationItem.GUID, title)
+	copyPath := strings.TrimSuffix(thumbnail, filepath.Ext(thumbnail)) + ".source" + strings.ToLower(filepath.Ext(artwork))
+	if err := copyFile(artwork, copyPath); err != nil {
+		return err
+	}
+	return ConvertImageToCorrectFormat(copyPath, thumbnail)
+}
+
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	_, err = io.Copy(out, in)
+	return err
+}
+
+func getMediaDuration(ctx context.Context, path string) (float64, error) {
+	out, err := run(
+		ctx,
+		"ffprobe",
+		"-v",
+		"error",
+		"-show_entries",
+		"format=duration",
+		"-of",
+		"csv=p=0",
+		path,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(strings.TrimSpace(out), 64)
+}
+
+// transcodeAudio encodes the first audio stream of src as MP3 with the settings of profile
+func transcodeAudio(ctx context.Context, src, dest string, profile AudioProfile) (uint64, error) {
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return 0, err
+	}
+	quality := []string{"-b:a", strings.ToLower(profile.Quality)}
+	if !strings.HasSuffix(strings.ToUpper(profile.Quality), "K") {
+		// yt-dlp's VBR scale goes to 10, lame's to 9
+		q, err := strconv.Atoi(profile.Quality)
+		if err != nil {
+			return 0, errors.New("invalid audio quality " + profile.Quality)
+		}
+		quality = []string{"-q:a", strconv.Itoa(min(q, 9))}
+	}
+	args := []string{"-y", "-loglevel", "error", "-i", src, "-map", "0:a:0", "-vn", "-c:a", "libmp3lame"}
+	args = append(args, quality...)
+	args = append(args, dest)
+	if _, err := run(ctx, "ffmpeg", args...); err != nil {
+		return 0, err
+	}
+	info, err := os.Stat(dest)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(info.Size()), nil
+}
@@ -0,0 +1,194 @@
+package rss
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+var MEDIA_BASE string = "tubecast/media"
+
+var DEFAULT_AUDIO_PROFILE = AudioProfile{Quality: "0"}
+
+// MediaStore indexes the uploaded audio by video and audio profile, so shows
+// adding the same video share one object. It is saved to MEDIA_BASE/index.json.
+type MediaStore struct {
+	mu      sync.Mutex
+	Entries map[string]*MediaEntry `json:"entries"`
+}
+
+type MediaEntry struct {
+	Enclosure Enclosure `json:"enclosure"`
+	// titles of the shows referencing the object, it is deleted once the last one drops it
+	Stations []string `json:"stations"`
+}
+
+func (profile AudioProfile) getKey() string {
+	return "mp3-q" + profile.Quality
+}
+
+func (metaStation *MetaStation) getAudioProfile() AudioProfile {
+	if len(metaStation.AudioProfile.Quality) == 0 {
+		return DEFAULT_AUDIO_PROFILE
+	}
+	return metaStation.AudioProfile
+}
+
+func getMediaKey(guid string, profile AudioProfile) string {
+	return guid + "_" + profile.getKey()
+}
+
+func loadMediaStore() *MediaStore {
+	store := &MediaStore{Entries: make(map[string]*MediaEntry)}
+	data, err := os.ReadFile(filepath.Join(MEDIA_BASE, "index.json"))
+	if err != nil {
+		return store
+	}
+	if err := json.Unmarshal(data, store); err != nil {
+		logError(err, "Load Media Store")
+	}
+	if store.Entries == nil {
+		store.Entries = make(map[string]*MediaEntry)
+	}
+	return store
+}
+
+// Atomatically save the index, the caller holds mu
+func (store *MediaStore) save() error {
+	if err := os.MkdirAll(MEDIA_BASE, 0o755); err != nil {
+		return err
+	}
+	path := filepath.Join(MEDIA_BASE, "index.json")
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// acquire references the stored object for the station, if there is one
+func (store *MediaStore) acquire(key, station string) (Enclosure, bool) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	entry, ok := store.Entries[key]
+	if !ok {
+		return Enclosure{}, false
+	}
+	if !slices.Contains(entry.Stations, station) {
+		entry.Stations = append(entry.Stations, station)
+		store.save()
+	}
+	return entry.Enclosure, true
+}
+
+func (store *MediaStore) add(key string, enclosure Enclosure, station string) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.Entries[key] = &MediaEntry{Enclosure: enclosure, Stations: []string{station}}
+	store.save()
+}
+
+// isShared reports whether a show other than station references the object
+func (store *MediaStore) isShared(key, station string) bool {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	entry, ok := store.Entries[key]
+	return ok && slices.ContainsFunc(entry.Stations, func(s string) bool { return s != station })
+}
+
+// release drops the reference of the station and reports whether it was the last one
+func (store *MediaStore) release(key, station string) bool {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	entry, ok := store.Entries[key]
+	if !ok {
+		return false
+	}
+	entry.Stations = slices.DeleteFunc(entry.Stations, func(s string) bool { return s == station })
+	if len(entry.Stations) > 0 {
+		store.save()
+		return false
+	}
+	delete(store.Entries, key)
+	store.save()
+	return true
+}
+
+var mediaLocks sync.Map
+
+// lockMedia serialises the upload and deletion of one object
+func lockMedia(key string) func() {
+	m, _ := mediaLocks.LoadOrStore(key, &sync.Mutex{})
+	mu := m.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+// acquireMedia references the stored object for the station, if there is one, while no upload or deletion of it runs
+func (metaStation *MetaStation) acquireMedia(key string) (Enclosure, bool) {
+	defer lockMedia(key)()
+	return Media.acquire(key, metaStation.Title)
+}
+
+// storeMedia publishes the downloaded audio of guid, or reuses the object another show already uploaded.
+// Either way the station holds a reference to it afterwards. Older episodes are evicted to make space when evict is set.
+func (metaStation *MetaStation) storeMedia(ctx context.Context, guid string, size uint64, evict bool) (string, Enclosure, error) {
+	key := getMediaKey(guid, metaStation.getAudioProfile())
+	defer lockMedia(key)()
+	localpath := Megh.getLocalAudioFilepath(guid, metaStation.Title)
+	if enclosure, ok := Media.acquire(key, metaStation.Title); ok {
+		os.Remove(localpath)
+		return key, enclosure, nil
+	}
+	if evict {
+		unlock := metaStation.lock()
+		metaStation.makeSpace(ctx, size)
+		unlock()
+	}
+	mediapath := Megh.getLocalMediaFilepath(key)
+	if err := os.Rename(localpath, mediapath); err != nil {
+		return "", Enclosure{}, err
+	}
+	share, err := Megh.upload(ctx, key, metaStation.Title, AUDIO)
+	if err != nil {
+		// keeps the download for the next attempt
+		os.Rename(mediapath, localpath)
+		return "", Enclosure{}, fmt.Errorf("could not upload audio: %w", err)
+	}
+	enclosure := Enclosure{
+		URL:    share,
+		Type:   "audio/mpeg",
+		Length: size,
+	}
+	Media.add(key, enclosure, metaStation.Title)
+	return key, enclosure, nil
+}
+
+// releaseMedia drops the station's reference and deletes the object when no other show uses it.
+// It reports whether the object was deleted.
+func (metaStation *MetaStation) releaseMedia(ctx context.Context, key string) (bool, error) {
+	defer lockMedia(key)()
+	if !Media.release(key, metaStation.Title) {
+		return false, nil
+	}
+	return true, Megh.deleteMedia(ctx, key)
+}
+
+// deleteItemFiles removes the files of an episode from storage and reports whether its audio was deleted
+func (metaStation *MetaStation) deleteItemFiles(ctx context.Context, metaStationItem MetaStationItem) (bool, error) {
+	if len(metaStationItem.MediaKey) == 0 {
+		// episodes stored before the media store keep their audio under the show title
+		return true, Megh.deleteEpisode(ctx, metaStationItem.GUID, metaStation.Title)
+	}
+	if err := Megh.deleteThumbnail(ctx, metaStationItem.GUID, metaStation.Title); err != nil {
+		return false, err
+	}
+	return metaStation.releaseMedia(ctx, metaStationItem.MediaKey)
+}
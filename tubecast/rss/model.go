@@ -2,26 +2,69 @@ package rss
 
 import (
 	"encoding/xml"
+	"regexp"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type Station struct {
-	XMLName          xml.Name      `xml:"channel"                json:"channel"`
-	ID               uuid.UUID     `xml:"id"                     json:"id"`
-	Title            string        `xml:"title"                  json:"title"`
-	ITunesImage      ITunesImage   `xml:"itunes:image"           json:"itunes_image"`
-	Description      string        `xml:"description"            json:"description"`
-	Items            []StationItem `xml:"item"                   json:"item"`
-	Language         string        `xml:"language"               json:"language"`
-	Copyright        string        `xml:"copyright"              json:"copyright"`
-	ITunesAuthor     string        `xml:"itunes:author"          json:"itunes_author"`
-	ITunesSubtitle   string        `xml:"itunes:subtitle"        json:"itunes_subtitle"`
-	ITunesSummary    string        `xml:"itunes:summary"         json:"itunes_summary"`
-	ITunesExplicit   string        `xml:"itunes:explicit"        json:"itunes_explicit"`
-	ITunesCategories []Category    `xml:"itunes:category"        json:"itunes_categories"`
-	Owner            ITunesOwner   `xml:"itunes:owner"           json:"itunes_owner"`
+	XMLName          xml.Name       `xml:"channel"                json:"channel"`
+	ID               uuid.UUID      `xml:"id"                     json:"id"`
+	Title            string         `xml:"title"                  json:"title"`
+	ITunesImage      ITunesImage    `xml:"itunes:image"           json:"itunes_image"`
+	Description      string         `xml:"description"            json:"description"`
+	Link             string         `xml:"link"                   json:"link"`
+	LastBuildDate    string         `xml:"lastBuildDate"          json:"last_build_date"`
+	PubDate          string         `xml:"pubDate,omitempty"      json:"pub_date"`
+	Generator        string         `xml:"generator"              json:"generator"`
+	TTL              int            `xml:"ttl,omitempty"          json:"ttl"` // minutes between refreshes
+	Items            []StationItem  `xml:"item"                   json:"item"`
+	Language         string         `xml:"language"               json:"language"`
+	Copyright        string         `xml:"copyright"              json:"copyright"`
+	ITunesAuthor     string         `xml:"itunes:author"          json:"itunes_author"`
+	ITunesSubtitle   string         `xml:"itunes:subtitle"        json:"itunes_subtitle"`
+	ITunesSummary    string         `xml:"itunes:summary"         json:"itunes_summary"`
+	ITunesExplicit   string         `xml:"itunes:explicit"        json:"itunes_explicit"`
+	ITunesCategories []Category     `xml:"itunes:category"        json:"itunes_categories"`
+	Owner            ITunesOwner    `xml:"itunes:owner"           json:"itunes_owner"`
+	ITunesType       string         `xml:"itunes:type"            json:"itunes_type"`
+	PodcastGUID      string         `xml:"podcast:guid"           json:"podcast_guid"`
+	PodcastLocked    *PodcastLocked `xml:"podcast:locked"         json:"podcast_locked"`
+	PodcastFunding   []Funding      `xml:"podcast:funding"        json:"podcast_funding"`
+	PodcastPersons   []Person       `xml:"podcast:person"         json:"podcast_persons"`
+	AtomLinks        []AtomLink     `xml:"atom:link"              json:"atom_links"`
+	FhArchive        *struct{}      `xml:"fh:archive,omitempty"   json:"fh_archive,omitempty"` // marks an RFC 5005 archive page
+}
+
+type PodcastLocked struct {
+	Owner string `xml:"owner,attr,omitempty" json:"owner"`
+	Value string `xml:",chardata"            json:"value"` // yes or no
+}
+
+// Funding links to a page where listeners can support the show
+type Funding struct {
+	Url  string `xml:"url,attr"  json:"url"`
+	Text string `xml:",chardata" json:"text"`
+}
+
+// Person is a podcast:person, role and group come from the Podcast Taxonomy Project
+type Person struct {
+	Name  string `xml:",chardata"            json:"name"`
+	Role  string `xml:"role,attr,omitempty"  json:"role,omitempty"`
+	Group string `xml:"group,attr,omitempty" json:"group,omitempty"`
+	Href  string `xml:"href,attr,omitempty"  json:"href,omitempty"`
+	Img   string `xml:"img,attr,omitempty"   json:"img,omitempty"`
+}
+
+type PodcastSeason struct {
+	Number int    `xml:",chardata"           json:"number"`
+	Name   string `xml:"name,attr,omitempty" json:"name,omitempty"`
+}
+
+type PodcastEpisode struct {
+	Number  float64 `xml:",chardata"              json:"number"`
+	Display string  `xml:"display,attr,omitempty" json:"display,omitempty"`
 }
 
 type Category struct {
@@ -37,21 +80,30 @@ type ITunesOwner struct {
 type StationItem struct {
 	// ID             string      `xml:"id,attr"                      json:"id"`
 	// ITunesTitle    string      `xml:"itunes:title"                 json:"itunes_title"`
-	GUID           string      `xml:"guid"                         json:"guid"`
-	Title          string      `xml:"title"                        json:"title"`
-	Enclosure      Enclosure   `xml:"enclosure"                    json:"enclosure"`
-	ITunesImage    ITunesImage `xml:"itunes:image"                 json:"itunes_image"`
-	Description    string      `xml:"description"                  json:"description"`
-	Link           string      `xml:"link"                         json:"link"`
-	PubDate        string      `xml:"pubDate"                      json:"pubDate"`
-	ITunesDuration string      `xml:"itunes:duration"              json:"itunes_duration"`
-	ITunesExplicit string      `xml:"itunes:explicit"              json:"itunes_explicit"`
-	ITunesAuthor   string      `xml:"itunes:author"                json:"itunes_author"`
-	ITunesSubtitle string      `xml:"itunes:subtitle"              json:"itunes_subtitle"`
-	ITunesSummary  string      `xml:"itunes:summary"               json:"itunes_summary"`
-	// ITunesEpisode     int       `xml:"itunes:episode,omitempty"     json:"itunes_episode"`
-	// ITunesSeason      int       `xml:"itunes:season,omitempty"      json:"itunes_season"`
-	// ITunesEpisodeType string    `xml:"itunes:episodeType"           json:"itunes_episode_type"`
+	GUID              string          `xml:"guid"                         json:"guid"`
+	Title             string          `xml:"title"                        json:"title"`
+	Enclosure         Enclosure       `xml:"enclosure"                    json:"enclosure"`
+	ITunesImage       ITunesImage     `xml:"itunes:image"                 json:"itunes_image"`
+	Description       string          `xml:"description"                  json:"description"`
+	Link              string          `xml:"link"                         json:"link"`
+	PubDate           string          `xml:"pubDate"                      json:"pubDate"`
+	ITunesDuration    string          `xml:"itunes:duration"              json:"itunes_duration"`
+	ITunesExplicit    string          `xml:"itunes:explicit"              json:"itunes_explicit"`
+	ITunesAuthor      string          `xml:"itunes:author"                json:"itunes_author"`
+	ITunesSubtitle    string          `xml:"itunes:subtitle"              json:"itunes_subtitle"`
+	ITunesSummary     string          `xml:"itunes:summary"               json:"itunes_summary"`
+	ContentEncoded    *CData          `xml:"content:encoded,omitempty"    json:"content_encoded,omitempty"`
+	PodcastSeason     *PodcastSeason  `xml:"podcast:season,omitempty"     json:"podcast_season,omitempty"`
+	PodcastEpisode    *PodcastEpisode `xml:"podcast:episode,omitempty"    json:"podcast_episode,omitempty"`
+	PodcastPersons    []Person        `xml:"podcast:person"               json:"podcast_persons,omitempty"`
+	ITunesEpisode     int             `xml:"itunes:episode,omitempty"     json:"itunes_episode"`
+	ITunesSeason      int             `xml:"itunes:season,omitempty"      json:"itunes_season"`
+	ITunesEpisodeType string          `xml:"itunes:episodeType"           json:"itunes_episode_type"`
+}
+
+// CData is written as a CDATA section, for HTML inside the feed
+type CData struct {
+	Text string `xml:",cdata"`
 }
 
 type ITunesImage struct {
@@ -64,44 +116,117 @@ type Enclosure struct {
 }
 
 type MetaStation struct {
-	ID                uuid.UUID         `json:"id"`
-	Title             string            `json:"title"`
-	Url               string            `json:"url"`
-	Description       string            `json:"description"`
-	Items             []MetaStationItem `json:"item"`
-	ChannelCount      uint32            `json:"channel_count"`
-	CreatedOn         time.Time         `json:"created_on"`
-	Language          string            `json:"language"`
-	Copyright         string            `json:"copyright"`
-	ITunesAuthor      string            `json:"itunes_author"`
-	ITunesSubtitle    string            `json:"itunes_subtitle"`
-	ITunesSummary     string            `json:"itunes_summary"`
-	ITunesImage       ITunesImage       `json:"itunes_image"`
-	ITunesExplicit    string            `json:"itunes_explicit"`
-	ITunesCategories  []Category        `json:"itunes_categories"`
-	Owner             ITunesOwner       `json:"itunes_owner"`
-	SubscribedChannel *Set[string]      `json:"subscribed_channel"`
+	ID               uuid.UUID         `json:"id"`
+	Title            string            `json:"title"`
+	Url              string            `json:"url"`
+	Description      string            `json:"description"`
+	Items            []MetaStationItem `json:"item"`
+	ChannelCount     uint32            `json:"channel_count"`
+	CreatedOn        time.Time         `json:"created_on"`
+	Language         string            `json:"language"`
+	Copyright        string            `json:"copyright"`
+	ITunesAuthor     string            `json:"itunes_author"`
+	ITunesSubtitle   string            `json:"itunes_subtitle"`
+	ITunesSummary    string            `json:"itunes_summary"`
+	ITunesImage      ITunesImage       `json:"itunes_image"`
+	ITunesExplicit   string            `json:"itunes_explicit"`
+	ITunesCategories []Category        `json:"itunes_categories"`
+	Owner            ITunesOwner       `json:"itunes_owner"`
+	Subscriptions    []Subscription    `json:"subscriptions"`
+	AudioProfile     AudioProfile      `json:"audio_profile"`
+	ITunesType       string            `json:"itunes_type,omitempty"`     // episodic when empty
+	Numbering        NumberingPolicy   `json:"numbering,omitempty"`       // manual when empty
+	FeedItemLimit    int               `json:"feed_item_limit,omitempty"` // FEED_ITEM_LIMIT when 0
+	TTL              int               `json:"ttl,omitempty"`             // minutes, SYNC_INTERVAL or DEFAULT_TTL when 0
+	ItemsChangedOn   time.Time         `json:"items_changed_on,omitempty"`
+	// podcast namespace, the guid is derived from ID unless set
+	PodcastGUID    string    `json:"podcast_guid,omitempty"`
+	PodcastLocked  bool      `json:"podcast_locked"`
+	PodcastFunding []Funding `json:"podcast_funding,omitempty"`
+	PodcastPersons []Person  `json:"podcast_persons,omitempty"` // the subscribed channels when empty
+	// Deprecated: older station files only; migrated into Subscriptions on load
+	SubscribedChannel *Set[string] `json:"subscribed_channel,omitempty"`
+	// video guid -> tombstone of the episodes removed from the show
+	Excluded map[string]Tombstone `json:"excluded,omitempty"`
+}
+
+// Tombstone records why a video was removed so Sync does not add it again
+type Tombstone struct {
+	Title     string          `json:"title"`
+	Link      string          `json:"link"`
+	Reason    TombstoneReason `json:"reason"`
+	RemovedOn time.Time       `json:"removed_on"`
+}
+
+type Subscription struct {
+	Kind SubscriptionKind `json:"kind"`
+	// channel ID (UC...) or playlist ID; a handle until it has been resolved
+	ID     string             `json:"id"`
+	Name   string             `json:"name"`
+	Filter SubscriptionFilter `json:"filter"`
+	// cache validators of the YouTube Atom feed
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	Backfill     *Backfill `json:"backfill,omitempty"`
+}
+
+// AudioProfile is the encoding of a show's episodes, part of the key of its stored media
+type AudioProfile struct {
+	Quality string `json:"quality"` // yt-dlp --audio-quality: 0 (best) to 10 VBR, or a bitrate such as 64K
+}
+
+// Backfill tracks the import of a subscription's upload history
+type Backfill struct {
+	OldestFirst bool `json:"oldest_first"`
+	// number of entries already walked, counted from the end the import started at
+	Cursor uint `json:"cursor"`
+	Done   bool `json:"done"`
+}
+
+// SubscriptionFilter decides which uploads of a subscribed channel become episodes
+type SubscriptionFilter struct {
+	MinDuration     uint32 `json:"min_duration,omitempty"` // seconds
+	MaxDuration     uint32 `json:"max_duration,omitempty"` // seconds
+	IncludePattern  string `json:"include_pattern,omitempty"`
+	ExcludePattern  string `json:"exclude_pattern,omitempty"`
+	SkipShorts      bool   `json:"skip_shorts"`
+	SkipLive        bool   `json:"skip_live"`
+	SkipMembersOnly bool   `json:"skip_members_only"`
+	// video id -> reason, so rejected videos are not evaluated again
+	Rejected map[string]string `json:"rejected,omitempty"`
+	// the patterns compiled by validate
+	include *regexp.Regexp
+	exclude *regexp.Regexp
 }
 
 type MetaStationItem struct {
-	GUID           string      `json:"guid"`
-	ITunesAuthor   string      `json:"itunes_author"`
-	ChannelID      string      `json:"channel_id"`
-	AddedOn        time.Time   `json:"added_on"`
-	ITunesExplicit string      `json:"itunes_explicit"`
-	Title          string      `json:"title"`
-	Description    string      `json:"description"`
-	ITunesSummary  string      `json:"itunes_summary"`
-	ITunesDuration string      `json:"itunes_duration"`
-	Views          uint32      `json:"views"`
-	PubDate        string      `json:"pubDate"`
-	ITunesImage    ITunesImage `json:"itunes_image"`
-	Enclosure      Enclosure   `json:"enclosure"`
-	ITunesSubtitle string      `json:"itunes_subtitle"`
-	Link           string      `json:"link"`
-	// ITunesEpisode     int       `json:"itunes_episode"`
-	// ITunesSeason      int       `json:"itunes_season"`
-	// ITunesEpisodeType string    `json:"itunes_episode_type"`
+	GUID              string          `json:"guid"`
+	ITunesAuthor      string          `json:"itunes_author"`
+	ChannelID         string          `json:"channel_id"`
+	AddedOn           time.Time       `json:"added_on"`
+	ITunesExplicit    string          `json:"itunes_explicit"`
+	Title             string          `json:"title"`
+	Description       string          `json:"description"`
+	ITunesSummary     string          `json:"itunes_summary"`
+	ITunesDuration    string          `json:"itunes_duration"`
+	Views             uint32          `json:"views"`
+	PubDate           string          `json:"pubDate"`
+	ITunesImage       ITunesImage     `json:"itunes_image"`
+	Enclosure         Enclosure       `json:"enclosure"`
+	ITunesSubtitle    string          `json:"itunes_subtitle"`
+	Link              string          `json:"link"`
+	SourceUrl         string          `json:"source_url,omitempty"`
+	Extractor         string          `json:"extractor,omitempty"` // yt-dlp extractor key, empty for older YouTube items
+	MediaKey          string          `json:"media_key,omitempty"` // key in the MediaStore, empty for older items
+	PartOf            string          `json:"part_of,omitempty"`
+	Part              int             `json:"part,omitempty"`
+	PartCount         int             `json:"part_count,omitempty"`
+	PodcastSeason     *PodcastSeason  `json:"podcast_season,omitempty"`
+	PodcastEpisode    *PodcastEpisode `json:"podcast_episode,omitempty"`
+	PodcastPersons    []Person        `json:"podcast_persons,omitempty"` // the uploading channel when empty
+	ITunesEpisode     int             `json:"itunes_episode,omitempty"`
+	ITunesSeason      int             `json:"itunes_season,omitempty"`
+	ITunesEpisodeType string          `json:"itunes_episode_type,omitempty"` // full when empty
 }
 
 type User struct {
@@ -134,8 +259,93 @@ type Usage struct {
 	FileCount      uint64 `json:"file_count"`
 }
 
+type VideoInfo struct {
+	ID           string  `json:"id"`
+	Title        string  `json:"title"`
+	Description  string  `json:"description"`
+	Duration     float64 `json:"duration"`
+	LiveStatus   string  `json:"live_status"`
+	Availability string  `json:"availability"`
+	Width        int     `json:"width"`
+	Height       int     `json:"height"`
+	WebpageUrl   string  `json:"webpage_url"`
+}
+
+type Chapter struct {
+	StartTime float64 `json:"start_time"`
+	EndTime   float64 `json:"end_time"`
+	Title     string  `json:"title"`
+}
+
+// EpisodePart is a slice of a video in seconds
+type EpisodePart struct {
+	Start float64
+	End   float64
+}
+
+// IngestJob is the on-disk record of a video being added to a station
+type IngestJob struct {
+	Station        string          `json:"station"`
+	Source         VideoSource     `json:"source"`
+	Author         string          `json:"author"`
+	ChannelFeedUrl string          `json:"channel_feed_url"`
+	State          JobState        `json:"state"`
+	Attempts       int             `json:"attempts"`
+	Error          string          `json:"error,omitempty"`
+	ErrorClass     string          `json:"error_class,omitempty"`
+	NextRetry      time.Time       `json:"next_retry,omitempty"`
+	Item           MetaStationItem `json:"item"`
+	Duration       float64         `json:"duration"`   // seconds
+	AudioSize      uint64          `json:"audio_size"` // bytes, set once the download finished
+	Tagged         bool            `json:"tagged"`
+	ThumbnailError string          `json:"thumbnail_error,omitempty"` // the episode uses the show cover
+	Artwork        string          `json:"artwork,omitempty"`         // remote artwork of a mirrored podcast episode
+	Backfill       bool            `json:"backfill,omitempty"`        // added by a backfill, which does not evict episodes
+	CreatedOn      time.Time       `json:"created_on"`
+	UpdatedOn      time.Time       `json:"updated_on"`
+}
+
+type SyncReport struct {
+	Shows []ShowReport
+}
+
+type ShowReport struct {
+	Title    string
+	Channels []ChannelReport
+	Err      error
+}
+
+type ChannelReport struct {
+	Channel string
+	Added   int
+	Failed  []string // "<video id>: <error>"
+	Err     error
+}
+
 type EpisodeInfo struct {
-	Title   string
-	Author  string
-	PubDate string
+	GUID        string
+	Title       string
+	Author      string
+	PubDate     string
+	Season      int
+	Episode     int
+	EpisodeType string
+}
+
+type FailedInfo struct {
+	GUID       string
+	Title      string
+	Link       string
+	ErrorClass string
+	Error      string
+	Attempts   int
+	NextRetry  time.Time
+	Dead       bool
+}
+
+type ExcludedInfo struct {
+	GUID      string
+	Title     string
+	Reason    TombstoneReason
+	RemovedOn time.Time
 }
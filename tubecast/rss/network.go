@@ -0,0 +1,131 @@
+package rss
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const DEFAULT_USER_AGENT = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+
+// NetworkProfile is applied to every yt-dlp call, every HTTP request and every storage upload
+type NetworkProfile struct {
+	Proxy           string // http://, https://, socks5:// or socks5h:// url, empty for a direct connection
+	RateLimit       string // download rate in yt-dlp's format, e.g. 500K or 2M, empty for no limit
+	UserAgent       string
+	Retries         int
+	FragmentRetries int
+}
+
+func (network *NetworkProfile) validate() error {
+	if len(network.Proxy) > 0 {
+		u, err := url.Parse(network.Proxy)
+		if err != nil {
+			return err
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+		}
+	}
+	if network.Retries < 0 || network.FragmentRetries < 0 {
+		return errors.New("retries cannot be negative")
+	}
+	return nil
+}
+
+// getYtdlpArgs returns the yt-dlp options of the profile
+func (network *NetworkProfile) getYtdlpArgs() []string {
+	args := []string{
+		"--user-agent", network.UserAgent,
+		"--retries", strconv.Itoa(network.Retries),
+		"--fragment-retries", strconv.Itoa(network.FragmentRetries),
+	}
+	if len(network.Proxy) > 0 {
+		args = append(args, "--proxy", network.Proxy)
+	}
+	if len(network.RateLimit) > 0 {
+		args = append(args, "--limit-rate", network.RateLimit)
+	}
+	return args
+}
+
+// getIaEnv returns the environment of the ia command, which reads the proxy from the standard variables
+func (network *NetworkProfile) getIaEnv() []string {
+	env := os.Environ()
+	if len(network.Proxy) > 0 {
+		env = append(env, "HTTP_PROXY="+network.Proxy, "HTTPS_PROXY="+network.Proxy)
+	}
+	return env
+}
+
+// proxy -> transport, shared by the clients of every profile using the proxy so their connections are reused
+var transports sync.Map
+
+// getTransport returns the transport for the proxy of the profile, building it on first use
+func (network *NetworkProfile) getTransport() *http.Transport {
+	if transport, ok := transports.Load(network.Proxy); ok {
+		return transport.(*http.Transport)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if proxy, err := url.Parse(network.Proxy); err == nil && len(network.Proxy) > 0 {
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	actual, _ := transports.LoadOrStore(network.Proxy, transport)
+	return actual.(*http.Transport)
+}
+
+func (network *NetworkProfile) getHttpClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &networkTransport{
+			base:    network.getTransport(),
+			network: network,
+		},
+	}
+}
+
+// networkTransport sets the user agent and retries requests without a body on
+// connection errors, 429 and 5xx responses
+type networkTransport struct {
+	base    http.RoundTripper
+	network *NetworkProfile
+}
+
+func (transport *networkTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if len(transport.network.UserAgent) > 0 {
+		req.Header.Set("User-Agent", transport.network.UserAgent)
+	}
+	for attempt := 0; ; attempt++ {
+		resp, err := transport.base.RoundTrip(req)
+		retry := err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		if !retry || req.Body != nil || attempt >= transport.network.Retries {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		if err := sleep(req.Context(), time.Duration(attempt+1)*time.Second); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
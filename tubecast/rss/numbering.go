@@ -0,0 +1,139 @@
+package rss
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+// NumberingPolicy decides how the season and episode numbers of a show are assigned
+type NumberingPolicy string
+
+const (
+	NUMBER_MANUALLY       NumberingPolicy = "manual"      // numbers are set per episode
+	NUMBER_BY_PUBLICATION NumberingPolicy = "publication" // episodes are numbered in publication order
+	NUMBER_BY_YEAR        NumberingPolicy = "year"        // the year is the season, episodes are numbered within it
+)
+
+const (
+	SHOW_EPISODIC = "episodic" // newest episodes first
+	SHOW_SERIAL   = "serial"   // episodes are meant to be listened to in order
+)
+
+const (
+	EPISODE_FULL    = "full"
+	EPISODE_TRAILER = "trailer"
+	EPISODE_BONUS   = "bonus"
+)
+
+var NUMBERING_POLICIES = []NumberingPolicy{NUMBER_MANUALLY, NUMBER_BY_PUBLICATION, NUMBER_BY_YEAR}
+var SHOW_TYPES = []string{SHOW_EPISODIC, SHOW_SERIAL}
+var EPISODE_TYPES = []string{EPISODE_FULL, EPISODE_TRAILER, EPISODE_BONUS}
+
+func validateNumbering(showType string, policy NumberingPolicy) error {
+	if !slices.Contains(SHOW_TYPES, showType) {
+		return fmt.Errorf("show type must be episodic or serial, got %q", showType)
+	}
+	if !slices.Contains(NUMBERING_POLICIES, policy) {
+		return fmt.Errorf("numbering must be manual, publication or year, got %q", policy)
+	}
+	return nil
+}
+
+func validateEpisodeNumbering(episodeType string, season, episode int) error {
+	if !slices.Contains(EPISODE_TYPES, episodeType) {
+		return fmt.Errorf("episode type must be full, trailer or bonus, got %q", episodeType)
+	}
+	if season < 0 || episode < 0 {
+		return fmt.Errorf("season and episode numbers cannot be negative")
+	}
+	return nil
+}
+
+func (metaStation *MetaStation) getITunesType() string {
+	if len(metaStation.ITunesType) == 0 {
+		return SHOW_EPISODIC
+	}
+	return metaStation.ITunesType
+}
+
+func (metaItem *MetaStationItem) getITunesEpisodeType() string {
+	if len(metaItem.ITunesEpisodeType) == 0 {
+		return EPISODE_FULL
+	}
+	return metaItem.ITunesEpisodeType
+}
+
+func (metaStation *MetaStation) isNumberedAutomatically() bool {
+	return metaStation.Numbering == NUMBER_BY_PUBLICATION || metaStation.Numbering == NUMBER_BY_YEAR
+}
+
+// getPublished returns the publication time of the episode, the time it was added when the pubDate is unreadable
+func (metaItem *MetaStationItem) getPublished() time.Time {
+	if pubDate, err := parsePubDate(metaItem.PubDate); err == nil {
+		return pubDate.UTC()
+	}
+	return metaItem.AddedOn.UTC()
+}
+
+// numberEpisode slots an episode into its season by publication date under an automatic policy:
+// it follows the episodes published before it and the later ones move up by one.
+// Trailers and bonus episodes get a season but no episode number. Removing an episode does not renumber the others.
+func (metaStation *MetaStation) numberEpisode(item *MetaStationItem) {
+	if !metaStation.isNumberedAutomatically() {
+		return
+	}
+	season := 0
+	if metaStation.Numbering == NUMBER_BY_YEAR {
+		season = item.getPublished().Year()
+	}
+	item.ITunesSeason = season
+	item.ITunesEpisode = 0
+	if item.getITunesEpisodeType() != EPISODE_FULL {
+		return
+	}
+	published := item.getPublished()
+	for _, other := range metaStation.Items {
+		if other.GUID != item.GUID && other.ITunesSeason == season && !other.getPublished().After(published) {
+			item.ITunesEpisode = max(item.ITunesEpisode, other.ITunesEpisode)
+		}
+	}
+	item.ITunesEpisode++
+	for i := range metaStation.Items {
+		other := &metaStation.Items[i]
+		if other.GUID != item.GUID && other.ITunesSeason == season && other.ITunesEpisode >= item.ITunesEpisode {
+			other.ITunesEpisode++
+		}
+	}
+}
+
+// renumberEpisodes numbers every episode in publication order, when a show switches to an automatic policy
+func (metaStation *MetaStation) renumberEpisodes() {
+	if !metaStation.isNumberedAutomatically() {
+		return
+	}
+	order := make([]int, len(metaStation.Items))
+	published := make([]time.Time, len(metaStation.Items))
+	for i, item := range metaStation.Items {
+		order[i] = i
+		published[i] = item.getPublished()
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return published[order[a]].Before(published[order[b]])
+	})
+	episodes := make(map[int]int) // season -> last episode number
+	for _, i := range order {
+		item := &metaStation.Items[i]
+		season := 0
+		if metaStation.Numbering == NUMBER_BY_YEAR {
+			season = published[i].Year()
+		}
+		item.ITunesSeason = season
+		item.ITunesEpisode = 0
+		if item.getITunesEpisodeType() == EPISODE_FULL {
+			episodes[season]++
+			item.ITunesEpisode = episodes[season]
+		}
+	}
+}
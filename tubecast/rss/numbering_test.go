@@ -0,0 +1,91 @@
+package rss
+
+import (
+	"testing"
+	"time"
+)
+
+func numberingFixture(policy NumberingPolicy) *MetaStation {
+	return &MetaStation{Numbering: policy, Items: []MetaStationItem{
+		{GUID: "b", PubDate: "Wed, 02 Feb 2022 10:00:00 GMT"},
+		{GUID: "a", PubDate: "Sat, 01 Jan 2022 10:00:00 GMT"},
+		{GUID: "trailer", PubDate: "Sun, 02 Jan 2022 10:00:00 GMT", ITunesEpisodeType: EPISODE_TRAILER},
+		{GUID: "c", PubDate: "Sun, 01 Jan 2023 10:00:00 GMT"},
+	}}
+}
+
+func getNumbers(metaStation *MetaStation) map[string][2]int {
+	numbers := make(map[string][2]int)
+	for _, item := range metaStation.Items {
+		numbers[item.GUID] = [2]int{item.ITunesSeason, item.ITunesEpisode}
+	}
+	return numbers
+}
+
+func TestRenumberEpisodes(t *testing.T) {
+	for _, test := range []struct {
+		policy NumberingPolicy
+		want   map[string][2]int
+	}{
+		{NUMBER_BY_PUBLICATION, map[string][2]int{"a": {0, 1}, "trailer": {0, 0}, "b": {0, 2}, "c": {0, 3}}},
+		{NUMBER_BY_YEAR, map[string][2]int{"a": {2022, 1}, "trailer": {2022, 0}, "b": {2022, 2}, "c": {2023, 1}}},
+		{NUMBER_MANUALLY, map[string][2]int{"a": {0, 0}, "trailer": {0, 0}, "b": {0, 0}, "c": {0, 0}}},
+	} {
+		metaStation := numberingFixture(test.policy)
+		metaStation.renumberEpisodes()
+		for guid, want := range test.want {
+			if got := getNumbers(metaStation)[guid]; got != want {
+				t.Errorf("%s: episode %s = %v, want %v", test.policy, guid, got, want)
+			}
+		}
+	}
+}
+
+func TestNumberEpisodeInPublicationOrder(t *testing.T) {
+	metaStation := numberingFixture(NUMBER_BY_PUBLICATION)
+	metaStation.renumberEpisodes()
+
+	// removing an episode leaves the other numbers alone
+	metaStation.Items = metaStation.Items[1:]
+	item := MetaStationItem{GUID: "d", PubDate: "Mon, 02 Jan 2023 10:00:00 GMT"}
+	metaStation.numberEpisode(&item)
+	if item.ITunesEpisode != 4 {
+		t.Errorf("new episode = %d, want 4", item.ITunesEpisode)
+	}
+	metaStation.Items = append(metaStation.Items, item)
+	want := map[string][2]int{"a": {0, 1}, "trailer": {0, 0}, "c": {0, 3}, "d": {0, 4}}
+	for guid, numbers := range getNumbers(metaStation) {
+		if numbers != want[guid] {
+			t.Errorf("episode %s = %v, want %v", guid, numbers, want[guid])
+		}
+	}
+
+	// a backfilled older episode takes its place by publication date and the later ones move up
+	metaStation.Items = append(metaStation.Items, MetaStationItem{GUID: "old", PubDate: "Fri, 01 Jan 2021 10:00:00 GMT"})
+	metaStation.numberEpisode(&metaStation.Items[len(metaStation.Items)-1])
+	want = map[string][2]int{"old": {0, 1}, "a": {0, 2}, "trailer": {0, 0}, "c": {0, 4}, "d": {0, 5}}
+	for guid, numbers := range getNumbers(metaStation) {
+		if numbers != want[guid] {
+			t.Errorf("episode %s = %v, want %v", guid, numbers, want[guid])
+		}
+	}
+}
+
+func TestNumberEpisodeByYear(t *testing.T) {
+	metaStation := numberingFixture(NUMBER_BY_YEAR)
+	metaStation.renumberEpisodes()
+	for _, test := range []struct {
+		item MetaStationItem
+		want [2]int
+	}{
+		{MetaStationItem{GUID: "d", PubDate: "Mon, 02 Jan 2023 10:00:00 GMT"}, [2]int{2023, 2}},
+		{MetaStationItem{GUID: "e", PubDate: "not a date", AddedOn: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)}, [2]int{2024, 1}},
+		{MetaStationItem{GUID: "bonus", PubDate: "Mon, 02 Jan 2023 10:00:00 GMT", ITunesEpisodeType: EPISODE_BONUS}, [2]int{2023, 0}},
+	} {
+		item := test.item
+		metaStation.numberEpisode(&item)
+		if got := [2]int{item.ITunesSeason, item.ITunesEpisode}; got != test.want {
+			t.Errorf("episode %s = %v, want %v", item.GUID, got, test.want)
+		}
+	}
+}
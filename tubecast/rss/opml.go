@@ -0,0 +1,281 @@
+package rss
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Opml struct {
+	XMLName xml.Name  `xml:"opml"`
+	Version string    `xml:"version,attr"`
+	Head    OpmlHead  `xml:"head"`
+	Body    []Outline `xml:"body>outline"`
+}
+
+type OpmlHead struct {
+	Title       string `xml:"title"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+	OwnerName   string `xml:"ownerName,omitempty"`
+	OwnerEmail  string `xml:"ownerEmail,omitempty"`
+}
+
+// Outline is a show (type rss) whose children link to its subscriptions, or a folder of outlines
+type Outline struct {
+	Text        string    `xml:"text,attr"`
+	Title       string    `xml:"title,attr,omitempty"`
+	Type        string    `xml:"type,attr,omitempty"`
+	XmlUrl      string    `xml:"xmlUrl,attr,omitempty"`
+	HtmlUrl     string    `xml:"htmlUrl,attr,omitempty"`
+	Url         string    `xml:"url,attr,omitempty"`
+	Description string    `xml:"description,attr,omitempty"`
+	Outlines    []Outline `xml:"outline"`
+}
+
+type ImportReport struct {
+	Shows         []string
+	Subscriptions int
+	Failed        []string // "<show>: <outline>: <error>"
+}
+
+func (report ImportReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d shows, %d subscriptions imported", len(report.Shows), report.Subscriptions)
+	for _, failed := range report.Failed {
+		fmt.Fprintf(&b, "\n  %s", failed)
+	}
+	return b.String()
+}
+
+func (cloud *Cloud) getOpmlFilename() string {
+	return fmt.Sprintf("%s_shows.opml", Usr.Username)
+}
+
+func (cloud *Cloud) getLocalOpmlFilepath() string {
+	return filepath.Join(FEED_BASE, cloud.getOpmlFilename())
+}
+
+func (cloud *Cloud) getShareableOpmlUrl() string {
+	return cloud.getShareableFeedFileUrl(cloud.getOpmlFilename())
+}
+
+// getOpml lists every show with its feed URL. The subscriptions of a show are link outlines,
+// which podcast apps ignore and another TubeCast install subscribes to on import.
+func getOpml() (Opml, error) {
+	opml := Opml{
+		Version: "2.0",
+		Head: OpmlHead{
+			Title:       "TubeCast Shows",
+			DateCreated: time.Now().UTC().Format(PUB_DATE_FORMAT),
+			OwnerName:   Usr.Name,
+			OwnerEmail:  Usr.EmailId,
+		},
+	}
+	titles := make([]string, 0, len(StationNames.Value))
+	for title := range StationNames.Value {
+		titles = append(titles, title)
+	}
+	sort.Strings(titles)
+	for _, title := range titles {
+		metaStation, err := loadMetaStationFromLocal(Megh.getLocalStationFilepath(title))
+		if err != nil {
+			return Opml{}, err
+		}
+		outline := Outline{
+			Text:        title,
+			Title:       title,
+			Type:        "rss",
+			XmlUrl:      Megh.getShareableFeedUrl(title),
+			Description: metaStation.Description,
+		}
+		for _, sub := range metaStation.Subscriptions {
+			child := Outline{
+				Text: sub.getDisplayName(),
+				Type: "link",
+				Url:  sub.getFeedUrl(),
+			}
+			if sub.Kind == PODCAST_FEED {
+				child.Type = "rss"
+			}
+			outline.Outlines = append(outline.Outlines, child)
+		}
+		opml.Body = append(opml.Body, outline)
+	}
+	return opml, nil
+}
+
+// exportOpml writes the OPML of all shows next to the feeds and uploads it with them
+func exportOpml() (string, error) {
+	opml, err := getOpml()
+	if err != nil {
+		return "", err
+	}
+	data, err := xml.MarshalIndent(opml, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := writeFeedFile(Megh.getLocalOpmlFilepath(), append([]byte(xml.Header), data...)); err != nil {
+		return "", err
+	}
+	if !Megh.IsArchive {
+		return Megh.getShareableOpmlUrl(), nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+	defer cancel()
+	return Megh.upload(ctx, "", "", OPML)
+}
+
+// importOpml creates a show for every show or folder outline and subscribes it to the
+// channels, playlists and podcast feeds listed under it. Outlines at the top level that
+// are feeds themselves get a show of their own. Nothing is downloaded until the next sync.
+func importOpml(path string) (ImportReport, error) {
+	data, err := os.ReadFile(getLocalFilepath(path))
+	if err != nil {
+		return ImportReport{}, err
+	}
+	var opml Opml
+	if err := xml.Unmarshal(data, &opml); err != nil {
+		return ImportReport{}, fmt.Errorf("not an OPML file: %w", err)
+	}
+	var report ImportReport
+	for _, outline := range opml.Body {
+		title := outline.getShowTitle()
+		var children []Outline
+		switch {
+		case len(outline.Outlines) > 0:
+			children = outline.Outlines
+		case len(outline.XmlUrl) > 0 && !StationNames.Has(title) && !outline.isTubeCastFeed():
+			children = []Outline{outline}
+		default:
+			// a show of a TubeCast install, or an empty folder
+			continue
+		}
+		if len(title) == 0 {
+			report.Failed = append(report.Failed, "outline without a title")
+			continue
+		}
+		added, failed := importShow(title, outline.Description, children)
+		if added > 0 {
+			report.Shows = append(report.Shows, title)
+			report.Subscriptions += added
+		}
+		report.Failed = append(report.Failed, failed...)
+	}
+	return report, nil
+}
+
+func importShow(title, description string, items []Outline) (int, []string) {
+	var subs []Subscription
+	var failed []string
+	for _, outline := range outlines(items).flatten() {
+		if outline.isTubeCastFeed() {
+			// mirroring a TubeCast feed would copy episodes the show can get from their source
+			continue
+		}
+		sub, err := outline.getSubscription()
+		if err == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			err = sub.resolve(ctx)
+			cancel()
+		}
+		if err != nil {
+			logError(err, "Import OPML - "+outline.Text)
+			failed = append(failed, fmt.Sprintf("%s: %s: %v", title, outline.Text, err))
+			continue
+		}
+		subs = append(subs, sub)
+	}
+	if len(subs) == 0 {
+		return 0, failed
+	}
+	metaStation, err := getMetaStation(title, description)
+	if err != nil {
+		return 0, append(failed, fmt.Sprintf("%s: %v", title, err))
+	}
+	defer metaStation.lock()()
+	for _, sub := range subs {
+		if metaStation.getSubscription(sub.getKey()) == nil {
+			metaStation.Subscriptions = append(metaStation.Subscriptions, sub)
+		}
+	}
+	if _, err := metaStation.updateFeed(); err != nil {
+		logError(err, "Import OPML - Update Feed")
+	}
+	return len(subs), failed
+}
+
+type outlines []Outline
+
+// flatten returns the outlines that point somewhere, folders are walked
+func (list outlines) flatten() []Outline {
+	var out []Outline
+	for _, outline := range list {
+		if len(outline.XmlUrl) > 0 || len(outline.Url) > 0 {
+			out = append(out, outline)
+		}
+		out = append(out, outlines(outline.Outlines).flatten()...)
+	}
+	return out
+}
+
+func (outline *Outline) getShowTitle() string {
+	title := outline.Title
+	if len(title) == 0 {
+		title = outline.Text
+	}
+	return strings.TrimSpace(strings.ReplaceAll(title, "/", "-"))
+}
+
+// isTubeCastFeed reports whether the outline is the feed of a show published by TubeCast,
+// this install or another one, on GitHub Pages or Internet Archive
+func (outline *Outline) isTubeCastFeed() bool {
+	if len(outline.XmlUrl) == 0 {
+		return false
+	}
+	for _, prefix := range []string{Megh.FeedUrlPrefix, Megh.ArchiveUrlPrefix} {
+		if len(prefix) > 0 && strings.HasPrefix(outline.XmlUrl, prefix) {
+			return true
+		}
+	}
+	u, err := url.Parse(outline.XmlUrl)
+	if err != nil {
+		return false
+	}
+	host, path := strings.ToLower(u.Hostname()), strings.ToLower(u.Path)
+	switch {
+	case strings.HasSuffix(host, ".github.io"):
+		return strings.HasPrefix(path, "/tubecast/feed/")
+	case host == "archive.org" || strings.HasSuffix(host, ".archive.org"):
+		return strings.Contains(path, "_tubecast/")
+	}
+	return false
+}
+
+// getSubscription reads YouTube feed, channel and playlist URLs as channel subscriptions,
+// other feeds as podcasts to mirror and links as pages yt-dlp supports
+func (outline *Outline) getSubscription() (Subscription, error) {
+	link := outline.XmlUrl
+	if len(link) == 0 {
+		link = outline.Url
+	}
+	if !isHttpUrl(link) {
+		return Subscription{}, errors.New("outline has no http(s) URL")
+	}
+	sub, err := parseSubscription(link)
+	if err != nil {
+		return Subscription{}, err
+	}
+	if sub.Kind == SOURCE_URL && (len(outline.XmlUrl) > 0 || outline.Type == "rss") {
+		sub.Kind = PODCAST_FEED
+	}
+	// a known name saves resolving channel IDs one by one
+	sub.Name = strings.TrimSpace(outline.Text)
+	return sub, nil
+}
@@ -0,0 +1,53 @@
+package rss
+
+import "testing"
+
+func TestIsTubeCastFeed(t *testing.T) {
+	cloud := Megh
+	Megh = Cloud{
+		FeedUrlPrefix:    "https://me.github.io/TubeCast/feed/",
+		ArchiveUrlPrefix: "https://archive.org/download/me_tubecast/",
+	}
+	t.Cleanup(func() { Megh = cloud })
+
+	for link, want := range map[string]bool{
+		"https://me.github.io/TubeCast/feed/My_Show.xml":                           true,
+		"https://archive.org/download/me_tubecast/My_Show.xml":                     true,
+		"https://friend.github.io/TubeCast/feed/Their_Show.xml":                    true,
+		"https://archive.org/download/friend_tubecast/Their_Show.xml":              true,
+		"https://ia800100.us.archive.org/5/items/friend_tubecast/Their_Show.xml":   true,
+		"https://friend.github.io/blog/feed.xml":                                   false,
+		"https://archive.org/download/some_podcast/feed.xml":                       false,
+		"https://feeds.example.com/show.xml":                                       false,
+		"https://www.youtube.com/feeds/videos.xml?channel_id=UCxxxxxxxxxxxxxxxxxx": false,
+		"": false,
+	} {
+		outline := Outline{XmlUrl: link}
+		if got := outline.isTubeCastFeed(); got != want {
+			t.Errorf("isTubeCastFeed(%q) = %v, want %v", link, got, want)
+		}
+	}
+}
+
+func TestOutlineGetSubscription(t *testing.T) {
+	for _, test := range []struct {
+		outline Outline
+		kind    SubscriptionKind
+	}{
+		{Outline{Text: "Veritasium", XmlUrl: "https://www.youtube.com/feeds/videos.xml?channel_id=UCHnyfMqiRRG1u-2MsSQLbXA"}, CHANNEL_VIDEOS},
+		{Outline{Text: "A Podcast", Type: "rss", XmlUrl: "https://feeds.example.com/show.xml"}, PODCAST_FEED},
+		{Outline{Text: "A Page", Type: "link", Url: "https://vimeo.com/channels/staffpicks"}, SOURCE_URL},
+	} {
+		sub, err := test.outline.getSubscription()
+		if err != nil {
+			t.Errorf("%s: %v", test.outline.Text, err)
+			continue
+		}
+		if sub.Kind != test.kind || sub.Name != test.outline.Text {
+			t.Errorf("%s: got %s named %q", test.outline.Text, sub.Kind, sub.Name)
+		}
+	}
+	if _, err := (&Outline{Text: "Folder"}).getSubscription(); err == nil {
+		t.Error("expected an error for an outline without a URL")
+	}
+}
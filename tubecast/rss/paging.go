@@ -0,0 +1,117 @@
+package rss
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// getFeedItemLimit returns how many episodes the main feed lists, 0 for all of them
+func (metaStation *MetaStation) getFeedItemLimit() int {
+	if metaStation.FeedItemLimit > 0 {
+		return metaStation.FeedItemLimit
+	}
+	return FeedItemLimit
+}
+
+// getFeedItems splits the episodes, newest first, into the main feed and the archive pages.
+// Pages hold the same number of episodes as the main feed and are filled from the oldest
+// episode on, so only the newest page changes when episodes leave the main feed.
+func (metaStation *MetaStation) getFeedItems() ([]MetaStationItem, [][]MetaStationItem) {
+	items := metaStation.getPublishedItems()
+	limit := metaStation.getFeedItemLimit()
+	if limit <= 0 || len(items) <= limit {
+		return items, nil
+	}
+	archived := items[limit:]
+	var pages [][]MetaStationItem
+	for end := len(archived); end > 0; end -= limit {
+		pages = append(pages, archived[max(0, end-limit):end])
+	}
+	return items[:limit], pages
+}
+
+// getFeedPages returns the RFC 5005 archive documents of the show, the oldest first
+func (metaStation *MetaStation) getFeedPages() []Station {
+	_, pageItems := metaStation.getFeedItems()
+	pages := make([]Station, len(pageItems))
+	for i, items := range pageItems {
+		number := i + 1
+		page := metaStation.getStation()
+		page.Items = getStationItems(items)
+		// archive pages only change when their episodes do
+		page.PubDate = getLatestPubDate(items)
+		page.LastBuildDate = page.PubDate
+		page.FhArchive = &struct{}{}
+		page.AtomLinks = []AtomLink{
+			{Rel: "self", Type: "application/rss+xml", Href: Megh.getShareableFeedPageUrl(metaStation.Title, number)},
+			{Rel: "current", Type: "application/rss+xml", Href: Megh.getShareableFeedUrl(metaStation.Title)},
+		}
+		if number > 1 {
+			page.AtomLinks = append(page.AtomLinks, AtomLink{Rel: "prev-archive", Type: "application/rss+xml", Href: Megh.getShareableFeedPageUrl(metaStation.Title, number-1)})
+		}
+		if number < len(pageItems) {
+			page.AtomLinks = append(page.AtomLinks, AtomLink{Rel: "next-archive", Type: "application/rss+xml", Href: Megh.getShareableFeedPageUrl(metaStation.Title, number+1)})
+		}
+		pages[i] = page
+	}
+	return pages
+}
+
+// saveFeedPages writes the archive pages and uploads the ones that changed
+func (metaStation *MetaStation) saveFeedPages() error {
+	pages := metaStation.getFeedPages()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	for i, page := range pages {
+		number := i + 1
+		data, err := page.encodeFeed()
+		if err != nil {
+			return err
+		}
+		path := Megh.getLocalFeedPageFilepath(metaStation.Title, number)
+		if old, err := os.ReadFile(path); err == nil && bytes.Equal(old, data) {
+			continue
+		}
+		if err := writeFeedFile(path, data); err != nil {
+			return err
+		}
+		if Megh.IsArchive {
+			if _, err := Megh.upload(ctx, strconv.Itoa(number), metaStation.Title, FEED_PAGE); err != nil {
+				os.Remove(path)
+				return err
+			}
+		}
+	}
+	// pages left over after episodes were removed or the limit was raised
+	for number := len(pages) + 1; ; number++ {
+		path := Megh.getLocalFeedPageFilepath(metaStation.Title, number)
+		if _, err := os.Stat(path); err != nil {
+			break
+		}
+		os.Remove(path)
+		if Megh.IsArchive {
+			if _, err := ia(ctx, "delete", Megh.ArchiveId, Megh.getFeedPageFilename(metaStation.Title, number)); err != nil {
+				logError(err, "Save Feed Pages - Delete")
+			}
+		}
+	}
+	return nil
+}
+
+func (cloud *Cloud) getFeedPageFilename(title string, number int) string {
+	return fmt.Sprintf("%s_page%d.xml", strings.TrimSuffix(cloud.getFeedFilename(title), ".xml"), number)
+}
+
+func (cloud *Cloud) getLocalFeedPageFilepath(title string, number int) string {
+	return filepath.Join(FEED_BASE, cloud.getFeedPageFilename(title, number))
+}
+
+func (cloud *Cloud) getShareableFeedPageUrl(title string, number int) string {
+	return cloud.getShareableFeedFileUrl(cloud.getFeedPageFilename(title, number))
+}
@@ -0,0 +1,360 @@
+package rss
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PODCAST_EXTRACTOR marks episodes mirrored from another podcast's RSS feed
+const PODCAST_EXTRACTOR = "Podcast"
+
+type podcastFeed struct {
+	Channel struct {
+		Title       string        `xml:"title"`
+		Description string        `xml:"description"`
+		Language    string        `xml:"language"`
+		Link        string        `xml:"link"`
+		Author      string        `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd author"`
+		Image       ITunesImage   `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+		Explicit    string        `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd explicit"`
+		Items       []podcastItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type podcastItem struct {
+	GUID        string      `xml:"guid"`
+	Title       string      `xml:"title"`
+	Link        string      `xml:"link"`
+	Description string      `xml:"description"`
+	PubDate     string      `xml:"pubDate"`
+	Enclosure   Enclosure   `xml:"enclosure"`
+	Author      string      `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd author"`
+	Duration    string      `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
+	Image       ITunesImage `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+	Explicit    string      `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd explicit"`
+	Subtitle    string      `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd subtitle"`
+	Summary     string      `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd summary"`
+}
+
+// parsePodcastFeed reads a remote RSS feed into a Station, newest episodes first
+func parsePodcastFeed(data []byte) (Station, error) {
+	var feed podcastFeed
+	if err := xml.Unmarshal(data, &feed); err != nil {
+		return Station{}, err
+	}
+	channel := feed.Channel
+	if len(channel.Title) == 0 && len(channel.Items) == 0 {
+		return Station{}, errors.New("not a podcast feed")
+	}
+	station := Station{
+		Title:          channel.Title,
+		Description:    channel.Description,
+		Language:       channel.Language,
+		ITunesAuthor:   channel.Author,
+		ITunesImage:    channel.Image,
+		ITunesExplicit: channel.Explicit,
+	}
+	for _, item := range channel.Items {
+		if len(item.Enclosure.URL) == 0 {
+			continue
+		}
+		stationItem := StationItem{
+			GUID:           item.GUID,
+			Title:          item.Title,
+			Enclosure:      item.Enclosure,
+			ITunesImage:    item.Image,
+			Description:    item.Description,
+			Link:           item.Link,
+			PubDate:        item.PubDate,
+			ITunesDuration: item.Duration,
+			ITunesExplicit: item.Explicit,
+			ITunesAuthor:   item.Author,
+			ITunesSubtitle: item.Subtitle,
+			ITunesSummary:  item.Summary,
+		}
+		if len(stationItem.GUID) == 0 {
+			stationItem.GUID = item.Enclosure.URL
+		}
+		if len(stationItem.ITunesAuthor) == 0 {
+			stationItem.ITunesAuthor = channel.Author
+		}
+		if len(stationItem.ITunesImage.Href) == 0 {
+			stationItem.ITunesImage = channel.Image
+		}
+		if pubDate, err := parsePubDate(item.PubDate); err == nil {
+			stationItem.PubDate = pubDate.UTC().Format(PUB_DATE_FORMAT)
+		}
+		station.Items = append(station.Items, stationItem)
+	}
+	sort.SliceStable(station.Items, func(i, j int) bool {
+		a, _ := time.Parse(time.RFC1123, station.Items[i].PubDate)
+		b, _ := time.Parse(time.RFC1123, station.Items[j].PubDate)
+		return a.After(b)
+	})
+	return station, nil
+}
+
+// parsePubDate accepts the RFC 822 variants found in the wild
+func parsePubDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{time.RFC1123Z, time.RFC1123, "Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST", time.RFC822Z, time.RFC822} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid pubDate %q", value)
+}
+
+// parseITunesDuration reads seconds, MM:SS or HH:MM:SS
+func parseITunesDuration(value string) float64 {
+	var seconds float64
+	for _, part := range strings.Split(strings.TrimSpace(value), ":") {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return 0
+		}
+		seconds = seconds*60 + n
+	}
+	return seconds
+}
+
+// fetchPodcastFeed downloads and parses the feed of sub. It returns no station when the
+// feed has not changed since the validators stored on sub.
+func (sub *Subscription) fetchPodcastFeed(ctx context.Context) (*Station, atomValidators, error) {
+	validators := atomValidators{ETag: sub.ETag, LastModified: sub.LastModified}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sub.ID, nil)
+	if err != nil {
+		return nil, validators, err
+	}
+	if len(sub.ETag) > 0 {
+		req.Header.Set("If-None-Match", sub.ETag)
+	}
+	if len(sub.LastModified) > 0 {
+		req.Header.Set("If-Modified-Since", sub.LastModified)
+	}
+	resp, err := Network.getHttpClient(time.Minute).Do(req)
+	if err != nil {
+		return nil, validators, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, validators, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, validators, fmt.Errorf("podcast feed returned %s", resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, validators, err
+	}
+	station, err := parsePodcastFeed(data)
+	if err != nil {
+		return nil, validators, err
+	}
+	validators = atomValidators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	return &station, validators, nil
+}
+
+// getPodcastSource identifies a remote episode by its feed and GUID, GUIDs are only unique within a feed
+func (sub *Subscription) getPodcastSource(stationItem StationItem) VideoSource {
+	hash := sha1.Sum([]byte(sub.ID + "\n" + stationItem.GUID))
+	return VideoSource{
+		Extractor: PODCAST_EXTRACTOR,
+		ID:        hex.EncodeToString(hash[:8]),
+		Url:       stationItem.Enclosure.URL,
+	}
+}
+
+// mirrorPodcast mirrors every episode of the feed that passes the filter
+func (metaStation *MetaStation) mirrorPodcast(sub *Subscription) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 6*time.Hour)
+	defer cancel()
+	report := metaStation.syncPodcastFeed(ctx, sub, 0)
+	unlock := metaStation.lock()
+	_, err := metaStation.updateFeed()
+	unlock()
+	if err != nil {
+		return "", err
+	}
+	if report.Err != nil {
+		return "", report.Err
+	}
+	if len(report.Failed) > 0 {
+		return "", fmt.Errorf("%d episodes could not be mirrored, they are retried on the next sync", len(report.Failed))
+	}
+	return Megh.getShareableFeedUrl(metaStation.Title), nil
+}
+
+// syncPodcastFeed mirrors the newest limit episodes of the feed, all of them when limit is 0
+func (metaStation *MetaStation) syncPodcastFeed(ctx context.Context, sub *Subscription, limit int) ChannelReport {
+	report := ChannelReport{Channel: sub.getDisplayName()}
+	// a full mirror must see every episode, not only those that changed
+	if limit == 0 {
+		sub.ETag, sub.LastModified = "", ""
+	}
+	station, validators, err := sub.fetchPodcastFeed(ctx)
+	if err != nil {
+		logError(err, "syncPodcastFeed - Fetch Feed")
+		report.Err = err
+		return report
+	}
+	if station == nil {
+		return report
+	}
+	items := station.Items
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	for _, stationItem := range items {
+		source := sub.getPodcastSource(stationItem)
+		guid := source.getGUID()
+		if metaStation.HasItem(guid) || metaStation.isExcluded(guid) || sub.Filter.isRejected(guid) || !metaStation.isRetryDue(guid) {
+			continue
+		}
+		info := VideoInfo{
+			ID:          guid,
+			Title:       stationItem.Title,
+			Description: stationItem.Description,
+			Duration:    parseITunesDuration(stationItem.ITunesDuration),
+		}
+		if reason := sub.Filter.check(info); reason != "" {
+			metaStation.updateSubscription(sub, func(sub *Subscription) {
+				sub.Filter.reject(guid, reason)
+			})
+			continue
+		}
+		job := getIngestJob(metaStation.Title, source, stationItem.ITunesAuthor, sub.ID)
+		if len(job.Item.Title) == 0 {
+			job.Item.Title = stationItem.Title
+			job.Item.Description = stationItem.Description
+			job.Item.ITunesSubtitle = stationItem.ITunesSubtitle
+			job.Item.ITunesSummary = stationItem.ITunesSummary
+			if duration := parseITunesDuration(stationItem.ITunesDuration); duration > 0 {
+				job.Item.ITunesDuration = formatDuration(duration)
+			}
+			job.Item.PubDate = stationItem.PubDate
+			job.Item.Link = stationItem.Link
+			// only the type, which lets downloadEnclosure keep MP3s as they are; the URL is set once the audio is uploaded
+			job.Item.Enclosure.Type = stationItem.Enclosure.Type
+			if len(stationItem.ITunesExplicit) > 0 {
+				job.Item.ITunesExplicit = stationItem.ITunesExplicit
+			}
+			job.Artwork = stationItem.ITunesImage.Href
+			job.save()
+		}
+		if _, err := metaStation.runIngestJob(ctx, job); err != nil {
+			logError(err, "syncPodcastFeed - Mirror Episode")
+			report.Failed = append(report.Failed, fmt.Sprintf("%s: %v", stationItem.Title, err))
+		} else {
+			report.Added++
+		}
+	}
+	// an unchanged feed is skipped, so failed episodes keep the old validators until they are retried
+	if len(report.Failed) == 0 && (len(validators.ETag) > 0 || len(validators.LastModified) > 0) {
+		metaStation.updateSubscription(sub, func(sub *Subscription) {
+			sub.ETag = validators.ETag
+			sub.LastModified = validators.LastModified
+		})
+	}
+	return report
+}
+
+// downloadEnclosure fetches the audio and artwork of a mirrored episode. MP3s are kept
+// as they are, other formats are transcoded to the show's audio profile.
+func (metaStation *MetaStation) downloadEnclosure(ctx context.Context, job *IngestJob) error {
+	metaStationItem := &job.Item
+	localpath := Megh.getLocalAudioFilepath(metaStationItem.GUID, metaStation.Title)
+	if len(job.Artwork) > 0 {
+		if err := metaStationItem.saveRemoteArtwork(ctx, job.Artwork, metaStation.Title); err != nil {
+			logError(err, "Download Enclosure - Artwork")
+		}
+	}
+	return Jobs.do(ctx, DOWNLOAD, job.Source.Url, func() error {
+		if err := os.MkdirAll(AUDIO_BASE, 0o755); err != nil {
+			return err
+		}
+		download := localpath + ".download"
+		defer os.Remove(download)
+		if err := downloadFile(ctx, job.Source.Url, download); err != nil {
+			return err
+		}
+		duration, err := getMediaDuration(ctx, download)
+		if err != nil {
+			return err
+		}
+		if MaximumDuration > 0 && duration > MaximumDuration.Seconds() {
+			return fmt.Errorf("episode needs to be shorter than %v: %w", MaximumDuration, errTooLong)
+		}
+		job.Duration = duration
+		if len(metaStationItem.ITunesDuration) == 0 {
+			metaStationItem.ITunesDuration = formatDuration(duration)
+		}
+		if strings.HasPrefix(metaStationItem.Enclosure.Type, "audio/mpeg") || isMp3(ctx, download) {
+			if err := os.Rename(download, localpath); err != nil {
+				return err
+			}
+		} else if _, err := transcodeAudio(ctx, download, localpath, metaStation.getAudioProfile()); err != nil {
+			return err
+		}
+		info, err := os.Stat(localpath)
+		if err != nil {
+			return err
+		}
+		job.AudioSize = uint64(info.Size())
+		return nil
+	})
+}
+
+func (metaStationItem *MetaStationItem) saveRemoteArtwork(ctx context.Context, link, title string) error {
+	if err := os.MkdirAll(THUMBNAIL_BASE, 0o755); err != nil {
+		return err
+	}
+	thumbnail := Megh.getLocalThumbnailFilepath(metaStationItem.GUID, title)
+	source, err := downloadImage(ctx, link, strings.TrimSuffix(thumbnail, ".png")+".source")
+	if err != nil {
+		return err
+	}
+	return ConvertImageToCorrectFormat(source, thumbnail)
+}
+
+func isMp3(ctx context.Context, path string) bool {
+	out, err := run(ctx, "ffprobe", "-v", "error", "-show_entries", "format=format_name", "-of", "csv=p=0", path)
+	return err == nil && strings.TrimSpace(out) == "mp3"
+}
+
+func downloadFile(ctx context.Context, link, dest string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return err
+	}
+	// downloads of long episodes are bounded by ctx instead of a client timeout
+	resp, err := Network.getHttpClient(0).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download of %s returned %s", link, resp.Status)
+	}
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(f, resp.Body)
+	return err
+}
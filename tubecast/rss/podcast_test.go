@@ -0,0 +1,166 @@
+package rss
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const podcastFixture = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd">
+  <channel>
+    <title>Fixture Show</title>
+    <description>A show for tests</description>
+    <language>en</language>
+    <itunes:author>Fixture Author</itunes:author>
+    <itunes:image href="https://example.com/cover.jpg"/>
+    <item>
+      <guid>older</guid>
+      <title>Older</title>
+      <pubDate>Mon, 2 Jan 2006 15:04:05 -0700</pubDate>
+      <enclosure url="https://example.com/older.mp3" length="100" type="audio/mpeg"/>
+      <itunes:duration>1:02:03</itunes:duration>
+    </item>
+    <item>
+      <title>Newer</title>
+      <pubDate>Tue, 03 Jan 2006 10:00:00 GMT</pubDate>
+      <enclosure url="https://example.com/newer.mp3" length="200" type="audio/mpeg"/>
+      <itunes:author>Guest</itunes:author>
+      <itunes:image href="https://example.com/newer.jpg"/>
+    </item>
+    <item>
+      <guid>no-enclosure</guid>
+      <title>Text only</title>
+    </item>
+  </channel>
+</rss>`
+
+func TestParsePodcastFeed(t *testing.T) {
+	station, err := parsePodcastFeed([]byte(podcastFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if station.Title != "Fixture Show" || station.ITunesAuthor != "Fixture Author" {
+		t.Errorf("channel = %q by %q", station.Title, station.ITunesAuthor)
+	}
+	if len(station.Items) != 2 {
+		t.Fatalf("got %d items, want the 2 with an enclosure", len(station.Items))
+	}
+	newer, older := station.Items[0], station.Items[1]
+	if newer.Title != "Newer" || older.Title != "Older" {
+		t.Errorf("items are not newest first: %q, %q", newer.Title, older.Title)
+	}
+	if newer.GUID != "https://example.com/newer.mp3" {
+		t.Errorf("GUID without <guid> = %q, want the enclosure URL", newer.GUID)
+	}
+	if newer.ITunesAuthor != "Guest" || older.ITunesAuthor != "Fixture Author" {
+		t.Errorf("authors = %q, %q", newer.ITunesAuthor, older.ITunesAuthor)
+	}
+	if older.ITunesImage.Href != "https://example.com/cover.jpg" {
+		t.Errorf("item without artwork got %q, want the channel image", older.ITunesImage.Href)
+	}
+	if older.PubDate != "Mon, 02 Jan 2006 22:04:05 GMT" {
+		t.Errorf("pubDate = %q, want it normalised to GMT", older.PubDate)
+	}
+}
+
+func TestParsePodcastFeedRejectsOtherXml(t *testing.T) {
+	if _, err := parsePodcastFeed([]byte(`<opml version="2.0"><head/></opml>`)); err == nil {
+		t.Error("expected an error for a document without a channel")
+	}
+	if _, err := parsePodcastFeed([]byte("not xml")); err == nil {
+		t.Error("expected an error for malformed XML")
+	}
+}
+
+func TestParsePubDate(t *testing.T) {
+	want := time.Date(2006, 1, 2, 22, 4, 5, 0, time.UTC)
+	for _, value := range []string{
+		"Mon, 02 Jan 2006 15:04:05 -0700",
+		"Mon, 2 Jan 2006 15:04:05 -0700",
+		" Mon, 02 Jan 2006 22:04:05 GMT ",
+		"02 Jan 06 15:04 -0700",
+	} {
+		got, err := parsePubDate(value)
+		if err != nil {
+			t.Errorf("parsePubDate(%q): %v", value, err)
+			continue
+		}
+		if !got.Truncate(time.Minute).Equal(want.Truncate(time.Minute)) {
+			t.Errorf("parsePubDate(%q) = %v, want %v", value, got.UTC(), want)
+		}
+	}
+	if _, err := parsePubDate("2006-01-02"); err == nil {
+		t.Error("expected an error for an ISO date")
+	}
+}
+
+func TestParseITunesDuration(t *testing.T) {
+	for value, want := range map[string]float64{
+		"3723":     3723,
+		"62:03":    3723,
+		"1:02:03":  3723,
+		" 90.5 ":   90.5,
+		"":         0,
+		"1:xx:03":  0,
+		"an hour":  0,
+		"00:00:07": 7,
+	} {
+		if got := parseITunesDuration(value); got != want {
+			t.Errorf("parseITunesDuration(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestFetchPodcastFeed(t *testing.T) {
+	const etag = `"v1"`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", "Tue, 03 Jan 2006 10:00:00 GMT")
+		w.Write([]byte(podcastFixture))
+	}))
+	defer server.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	sub := Subscription{Kind: PODCAST_FEED, ID: server.URL}
+	station, validators, err := sub.fetchPodcastFeed(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if station == nil || len(station.Items) != 2 {
+		t.Fatalf("station = %+v, want the fixture", station)
+	}
+	if validators.ETag != etag || validators.LastModified == "" {
+		t.Errorf("validators = %+v", validators)
+	}
+
+	sub.ETag, sub.LastModified = validators.ETag, validators.LastModified
+	station, unchanged, err := sub.fetchPodcastFeed(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if station != nil {
+		t.Error("a 304 response returned a station")
+	}
+	if unchanged != validators {
+		t.Errorf("a 304 response changed the validators to %+v", unchanged)
+	}
+}
+
+func TestFetchPodcastFeedError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	sub := Subscription{Kind: PODCAST_FEED, ID: server.URL}
+	if _, _, err := sub.fetchPodcastFeed(ctx); err == nil {
+		t.Error("expected an error for a 404")
+	}
+}
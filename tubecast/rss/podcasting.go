@@ -0,0 +1,194 @@
+package rss
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// namespace of podcast:guid, see https://podcastindex.org/namespace/1.0#guid
+var PODCAST_GUID_NAMESPACE = uuid.MustParse("ead4c236-bf58-58c6-a2c6-a6b28d128cb6")
+
+const (
+	PODCAST_TEXT_LIMIT    = 128 // funding text, person and season names
+	PODCAST_DISPLAY_LIMIT = 32  // episode display
+	DEFAULT_PERSON_ROLE   = "host"
+)
+
+// groups of the Podcast Taxonomy Project, https://podcasttaxonomy.com
+var PERSON_GROUPS = []string{
+	"creative direction",
+	"cast",
+	"writing",
+	"audio production",
+	"audio post-production",
+	"administration",
+	"visuals",
+	"community",
+	"misc.",
+	"video production",
+	"video post-production",
+}
+
+// getPodcastGUID returns the podcast:guid of the show, stable across feed URL changes
+func (metaStation *MetaStation) getPodcastGUID() string {
+	if len(metaStation.PodcastGUID) > 0 {
+		return metaStation.PodcastGUID
+	}
+	return uuid.NewSHA1(PODCAST_GUID_NAMESPACE, []byte(metaStation.ID.String())).String()
+}
+
+func (metaStation *MetaStation) getPodcastLocked() *PodcastLocked {
+	locked := PodcastLocked{Value: "no", Owner: metaStation.Owner.Email}
+	if metaStation.PodcastLocked {
+		locked.Value = "yes"
+	}
+	return &locked
+}
+
+// getPodcastPersons returns the configured people, or the subscribed channels as hosts
+func (metaStation *MetaStation) getPodcastPersons() []Person {
+	if len(metaStation.PodcastPersons) > 0 {
+		return metaStation.PodcastPersons
+	}
+	var persons []Person
+	for _, sub := range metaStation.Subscriptions {
+		switch sub.Kind {
+		case CHANNEL_VIDEOS, CHANNEL_STREAMS, CHANNEL_PODCASTS:
+			persons = append(persons, Person{
+				Name: sub.getDisplayName(),
+				Role: DEFAULT_PERSON_ROLE,
+				Href: sub.getFeedUrl(),
+			})
+		}
+	}
+	return persons
+}
+
+// getPodcastPersons returns the configured people, or the channel that uploaded the episode
+func (metaItem *MetaStationItem) getPodcastPersons() []Person {
+	if len(metaItem.PodcastPersons) > 0 || len(metaItem.ITunesAuthor) == 0 {
+		return metaItem.PodcastPersons
+	}
+	person := Person{Name: metaItem.ITunesAuthor, Role: DEFAULT_PERSON_ROLE}
+	if isHttpUrl(metaItem.ChannelID) {
+		person.Href = metaItem.ChannelID
+	}
+	return []Person{person}
+}
+
+// validatePodcast checks the podcast namespace elements of the feed
+func (station *Station) validatePodcast() error {
+	return errors.Join(station.getPodcastErrors()...)
+}
+
+func (station *Station) getPodcastErrors() []error {
+	var errs []error
+	if _, err := uuid.Parse(station.PodcastGUID); err != nil {
+		errs = append(errs, fmt.Errorf("podcast:guid %q is not a UUID", station.PodcastGUID))
+	}
+	if station.PodcastLocked != nil {
+		if station.PodcastLocked.Value != "yes" && station.PodcastLocked.Value != "no" {
+			errs = append(errs, fmt.Errorf("podcast:locked must be yes or no, got %q", station.PodcastLocked.Value))
+		}
+		if station.PodcastLocked.Value == "yes" {
+			if _, err := mail.ParseAddress(station.PodcastLocked.Owner); err != nil {
+				errs = append(errs, errors.New("podcast:locked needs the owner email of the show"))
+			}
+		}
+	}
+	for _, funding := range station.PodcastFunding {
+		if err := funding.validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, person := range station.PodcastPersons {
+		if err := person.validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, item := range station.Items {
+		if err := item.validatePodcast(); err != nil {
+			errs = append(errs, fmt.Errorf("item %s: %w", item.GUID, err))
+		}
+	}
+	return errs
+}
+
+func (stationItem *StationItem) validatePodcast() error {
+	var errs []error
+	if stationItem.PodcastSeason != nil {
+		if err := stationItem.PodcastSeason.validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if stationItem.PodcastEpisode != nil {
+		if err := stationItem.PodcastEpisode.validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, person := range stationItem.PodcastPersons {
+		if err := person.validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (funding *Funding) validate() error {
+	if !isHttpUrl(funding.Url) {
+		return fmt.Errorf("podcast:funding url %q is not a http(s) URL", funding.Url)
+	}
+	if len([]rune(funding.Text)) > PODCAST_TEXT_LIMIT {
+		return fmt.Errorf("podcast:funding text is longer than %d characters", PODCAST_TEXT_LIMIT)
+	}
+	return nil
+}
+
+func (person *Person) validate() error {
+	if len(person.Name) == 0 {
+		return errors.New("podcast:person has no name")
+	}
+	if len([]rune(person.Name)) > PODCAST_TEXT_LIMIT {
+		return fmt.Errorf("podcast:person %q is longer than %d characters", person.Name, PODCAST_TEXT_LIMIT)
+	}
+	if len(person.Group) > 0 && !slices.Contains(PERSON_GROUPS, person.Group) {
+		return fmt.Errorf("podcast:person %q has unknown group %q", person.Name, person.Group)
+	}
+	if len(person.Href) > 0 && !isHttpUrl(person.Href) {
+		return fmt.Errorf("podcast:person %q href is not a http(s) URL", person.Name)
+	}
+	if len(person.Img) > 0 && !isHttpUrl(person.Img) {
+		return fmt.Errorf("podcast:person %q img is not a http(s) URL", person.Name)
+	}
+	return nil
+}
+
+func (season *PodcastSeason) validate() error {
+	if season.Number < 1 {
+		return fmt.Errorf("podcast:season must be a positive number, got %d", season.Number)
+	}
+	if len([]rune(season.Name)) > PODCAST_TEXT_LIMIT {
+		return fmt.Errorf("podcast:season name is longer than %d characters", PODCAST_TEXT_LIMIT)
+	}
+	return nil
+}
+
+func (episode *PodcastEpisode) validate() error {
+	if episode.Number <= 0 {
+		return fmt.Errorf("podcast:episode must be a positive number, got %v", episode.Number)
+	}
+	if len([]rune(episode.Display)) > PODCAST_DISPLAY_LIMIT {
+		return fmt.Errorf("podcast:episode display is longer than %d characters", PODCAST_DISPLAY_LIMIT)
+	}
+	return nil
+}
+
+func isHttpUrl(link string) bool {
+	u, err := url.Parse(link)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && len(u.Host) > 0
+}
@@ -0,0 +1,130 @@
+package rss
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Stage int
+
+const (
+	METADATA Stage = iota
+	DOWNLOAD       // download and transcode
+	UPLOAD
+)
+
+// Scheduler bounds the work of every stage by its own worker pool and spaces
+// out requests by a global and a per-host rate limit.
+type Scheduler struct {
+	pools    map[Stage]chan struct{}
+	global   *rateLimiter
+	hostRate float64
+	mu       sync.Mutex
+	hosts    map[string]*rateLimiter
+}
+
+// NewScheduler creates a scheduler. Rates are requests per second, 0 disables the limit.
+func NewScheduler(metadataWorkers, downloadWorkers, uploadWorkers int, globalRate, hostRate float64) *Scheduler {
+	return &Scheduler{
+		pools: map[Stage]chan struct{}{
+			METADATA: make(chan struct{}, max(metadataWorkers, 1)),
+			DOWNLOAD: make(chan struct{}, max(downloadWorkers, 1)),
+			UPLOAD:   make(chan struct{}, max(uploadWorkers, 1)),
+		},
+		global:   newRateLimiter(globalRate),
+		hostRate: hostRate,
+		hosts:    make(map[string]*rateLimiter),
+	}
+}
+
+// do runs fn on a worker of the stage once the rate limits for the host of link allow it.
+// Jobs must not schedule other jobs from inside fn.
+func (scheduler *Scheduler) do(ctx context.Context, stage Stage, link string, fn func() error) error {
+	pool := scheduler.pools[stage]
+	select {
+	case pool <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-pool }()
+	if err := scheduler.global.wait(ctx); err != nil {
+		return err
+	}
+	if err := scheduler.getHostLimiter(link).wait(ctx); err != nil {
+		return err
+	}
+	return fn()
+}
+
+func (scheduler *Scheduler) getHostLimiter(link string) *rateLimiter {
+	if scheduler.hostRate <= 0 {
+		return nil
+	}
+	host := link
+	if u, err := url.Parse(link); err == nil && len(u.Hostname()) > 0 {
+		host = strings.TrimPrefix(u.Hostname(), "www.")
+	}
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+	limiter, ok := scheduler.hosts[host]
+	if !ok {
+		limiter = newRateLimiter(scheduler.hostRate)
+		scheduler.hosts[host] = limiter
+	}
+	return limiter
+}
+
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// newRateLimiter returns nil, which never waits, when perSecond is not positive
+func newRateLimiter(perSecond float64) *rateLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &rateLimiter{
+		interval: time.Duration(float64(time.Second) / perSecond),
+	}
+}
+
+func (limiter *rateLimiter) wait(ctx context.Context) error {
+	if limiter == nil {
+		return nil
+	}
+	limiter.mu.Lock()
+	now := time.Now()
+	at := limiter.next
+	if at.Before(now) {
+		at = now
+	}
+	limiter.next = at.Add(limiter.interval)
+	limiter.mu.Unlock()
+
+	timer := time.NewTimer(at.Sub(now))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+var stationLocks sync.Map
+
+// lock serialises changes to a station while its channels are synced concurrently. The station
+// is reloaded once locked, so changes apply to what syncs, jobs and the TUI saved meanwhile.
+// Usage: defer metaStation.lock()()
+func (metaStation *MetaStation) lock() func() {
+	m, _ := stationLocks.LoadOrStore(metaStation.Title, &sync.Mutex{})
+	mu := m.(*sync.Mutex)
+	mu.Lock()
+	metaStation.refresh()
+	return mu.Unlock
+}
@@ -0,0 +1,212 @@
+package rss
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	DESCRIPTION_LIMIT = 3000 // characters of the plain description, Apple Podcasts allows 4000
+	SUBTITLE_LIMIT    = 255
+)
+
+var (
+	urlPattern       = regexp.MustCompile(`https?://[^\s<>"]+`)
+	timestampPattern = regexp.MustCompile(`\b(?:(\d{1,2}):)?(\d{1,2}):(\d{2})\b`)
+	tagPattern       = regexp.MustCompile(`<[^>]*>`)
+	paragraphPattern = regexp.MustCompile(`\n\s*\n`)
+	// credit line older episodes were stored with, it is rendered by getShowNotes now
+	legacyCreditPattern = regexp.MustCompile(`(?m)^Link to (?:the YouTube Video|the original on [^:\n]+): \S+\n?`)
+	// elements whose content is not text, comments and declarations
+	droppedHtmlPattern = regexp.MustCompile(`(?is)<!--.*?-->|<![^>]*>|<\?[^>]*>|<(?:script|style|iframe|object|embed|noscript|template|title|head|svg|math)\b.*?</(?:script|style|iframe|object|embed|noscript|template|title|head|svg|math)\s*>`)
+	htmlTagPattern     = regexp.MustCompile(`<(/?)([a-zA-Z][a-zA-Z0-9]*)((?:[^>"']|"[^"]*"|'[^']*')*)>`)
+	htmlAttrPattern    = regexp.MustCompile(`([^\s"'=/>]+)(?:\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+)))?`)
+)
+
+// tags mirrored show notes may keep, every other tag is dropped and its text kept
+var ALLOWED_HTML_TAGS = []string{"p", "a", "br", "ul", "ol", "li", "b", "i", "em", "strong"}
+
+// ShowNotes are the renderings of an episode's description
+type ShowNotes struct {
+	Html        string
+	Description string
+	Subtitle    string
+}
+
+// getShowNotes renders the description with links, timestamps as deep links and credit to
+// the original video and channel. Mirrored podcasts keep their own HTML, stripped by sanitizeHtml.
+func (metaItem *MetaStationItem) getShowNotes() ShowNotes {
+	text := strings.TrimSpace(legacyCreditPattern.ReplaceAllString(metaItem.Description, ""))
+	isHtml := metaItem.Extractor == PODCAST_EXTRACTOR && tagPattern.MatchString(text)
+	var notes ShowNotes
+	var plain string
+	if isHtml {
+		text = sanitizeHtml(text)
+		notes.Html = text
+		plain = strings.TrimSpace(html.UnescapeString(tagPattern.ReplaceAllString(text, " ")))
+	} else {
+		notes.Html = metaItem.renderShowNotes(text)
+		plain = text
+	}
+	if credit := metaItem.getCredit(); len(credit) > 0 {
+		notes.Html = "<p>" + metaItem.getCreditHtml() + "</p>\n" + notes.Html
+		plain = credit + "\n\n" + plain
+	}
+	notes.Description = truncate(strings.TrimSpace(plain), DESCRIPTION_LIMIT)
+	notes.Subtitle = metaItem.ITunesSubtitle
+	if len(notes.Subtitle) == 0 || notes.Subtitle == metaItem.Description || utf8.RuneCountInString(notes.Subtitle) > SUBTITLE_LIMIT {
+		notes.Subtitle = getSummary(text, isHtml)
+	}
+	return notes
+}
+
+// sanitizeHtml strips the HTML of a third-party feed down to ALLOWED_HTML_TAGS without attributes,
+// except the href of links to http(s) URLs
+func sanitizeHtml(text string) string {
+	text = droppedHtmlPattern.ReplaceAllString(text, "")
+	escapeText := strings.NewReplacer("<", "&lt;", ">", "&gt;").Replace
+	var b strings.Builder
+	last := 0
+	for _, match := range htmlTagPattern.FindAllStringSubmatchIndex(text, -1) {
+		b.WriteString(escapeText(text[last:match[0]]))
+		last = match[1]
+		closing, name, attrs := text[match[2]:match[3]], strings.ToLower(text[match[4]:match[5]]), text[match[6]:match[7]]
+		if !slices.Contains(ALLOWED_HTML_TAGS, name) || (closing == "/" && name == "br") {
+			continue
+		}
+		if closing == "/" {
+			fmt.Fprintf(&b, "</%s>", name)
+			continue
+		}
+		if name == "a" {
+			if href := getHref(attrs); isHttpUrl(href) {
+				fmt.Fprintf(&b, `<a href="%s">`, html.EscapeString(href))
+				continue
+			}
+		}
+		fmt.Fprintf(&b, "<%s>", name)
+	}
+	b.WriteString(escapeText(text[last:]))
+	return b.String()
+}
+
+func getHref(attrs string) string {
+	for _, attr := range htmlAttrPattern.FindAllStringSubmatch(attrs, -1) {
+		if strings.EqualFold(attr[1], "href") {
+			return strings.TrimSpace(html.UnescapeString(attr[2] + attr[3] + attr[4]))
+		}
+	}
+	return ""
+}
+
+// renderShowNotes turns plain text into paragraphs, linking URLs and, for YouTube, timestamps
+func (metaItem *MetaStationItem) renderShowNotes(text string) string {
+	var paragraphs []string
+	for _, paragraph := range paragraphPattern.Split(text, -1) {
+		if len(strings.TrimSpace(paragraph)) == 0 {
+			continue
+		}
+		lines := strings.Split(strings.TrimSpace(paragraph), "\n")
+		for i, line := range lines {
+			lines[i] = metaItem.renderLine(line)
+		}
+		paragraphs = append(paragraphs, "<p>"+strings.Join(lines, "<br>\n")+"</p>")
+	}
+	return strings.Join(paragraphs, "\n")
+}
+
+func (metaItem *MetaStationItem) renderLine(line string) string {
+	var b strings.Builder
+	last := 0
+	for _, match := range urlPattern.FindAllStringIndex(line, -1) {
+		link := strings.TrimRight(line[match[0]:match[1]], ".,;:!?)]}'")
+		b.WriteString(metaItem.renderTimestamps(line[last:match[0]]))
+		fmt.Fprintf(&b, `<a href="%s">%s</a>`, html.EscapeString(link), html.EscapeString(link))
+		last = match[0] + len(link)
+	}
+	b.WriteString(metaItem.renderTimestamps(line[last:]))
+	return b.String()
+}
+
+func (metaItem *MetaStationItem) renderTimestamps(text string) string {
+	text = html.EscapeString(text)
+	if !(VideoSource{Extractor: metaItem.Extractor}).isYoutube() || len(metaItem.Link) == 0 {
+		return text
+	}
+	return timestampPattern.ReplaceAllStringFunc(text, func(timestamp string) string {
+		parts := timestampPattern.FindStringSubmatch(timestamp)
+		hours, _ := strconv.Atoi(parts[1])
+		minutes, _ := strconv.Atoi(parts[2])
+		seconds, _ := strconv.Atoi(parts[3])
+		if seconds >= 60 || (len(parts[1]) > 0 && minutes >= 60) {
+			return timestamp
+		}
+		link := getTimestampLink(*metaItem, hours*3600+minutes*60+seconds)
+		return fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(link), timestamp)
+	})
+}
+
+// getCredit names the original video and the channel that published it
+func (metaItem *MetaStationItem) getCredit() string {
+	if !isHttpUrl(metaItem.Link) {
+		return ""
+	}
+	credit := "Original: " + metaItem.Link
+	if len(metaItem.ITunesAuthor) > 0 {
+		credit += " by " + metaItem.ITunesAuthor
+	}
+	return credit
+}
+
+func (metaItem *MetaStationItem) getCreditHtml() string {
+	site := "the original"
+	switch {
+	case (VideoSource{Extractor: metaItem.Extractor}).isYoutube():
+		site = "YouTube"
+	case metaItem.Extractor == PODCAST_EXTRACTOR:
+		site = "the original podcast"
+	case len(metaItem.Extractor) > 0:
+		site = metaItem.Extractor
+	}
+	credit := fmt.Sprintf(`<a href="%s">Listen to the original on %s</a>`, html.EscapeString(metaItem.Link), html.EscapeString(site))
+	if len(metaItem.ITunesAuthor) == 0 {
+		return credit
+	}
+	author := html.EscapeString(metaItem.ITunesAuthor)
+	if isHttpUrl(metaItem.ChannelID) {
+		author = fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(metaItem.ChannelID), author)
+	}
+	return credit + " by " + author + "."
+}
+
+// getSummary returns the first line of the description that is not only links or timestamps
+func getSummary(text string, isHtml bool) string {
+	if isHtml {
+		text = html.UnescapeString(tagPattern.ReplaceAllString(text, "\n"))
+	}
+	for _, line := range strings.Split(text, "\n") {
+		rest := timestampPattern.ReplaceAllString(urlPattern.ReplaceAllString(line, ""), "")
+		if len(strings.Trim(rest, " \t-–—|:•*#")) > 0 {
+			return truncate(strings.TrimSpace(line), SUBTITLE_LIMIT)
+		}
+	}
+	return ""
+}
+
+// truncate shortens text to limit characters at a word boundary
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	cut := string(runes[:limit-1])
+	if i := strings.LastIndexAny(cut, " \n"); i > len(cut)/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " \n.,;:") + "…"
+}
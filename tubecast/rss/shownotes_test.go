@@ -0,0 +1,46 @@
+package rss
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeHtml(t *testing.T) {
+	for input, want := range map[string]string{
+		`<p class="intro">Hello <b>world</b></p>`:                               `<p>Hello <b>world</b></p>`,
+		`<P>Line one<BR/>line two</P>`:                                          `<p>Line one<br>line two</p>`,
+		`<a href="https://example.com/?a=1&amp;b=2" onclick="steal()">link</a>`: `<a href="https://example.com/?a=1&amp;b=2">link</a>`,
+		`<a href='javascript:alert(1)'>bad</a>`:                                 `<a>bad</a>`,
+		`<a title="x href=https://evil.example">t</a>`:                          `<a>t</a>`,
+		`<a title='href=x' href="https://ok.example">t</a>`:                     `<a href="https://ok.example">t</a>`,
+		`<img src="x" onerror="alert(1)">caption`:                               `caption`,
+		`<div><span>kept text</span></div>`:                                     `kept text`,
+		`before<script>alert("<p>")</script>after`:                              `beforeafter`,
+		`<style>p { color: red }</style><p>styled</p>`:                          `<p>styled</p>`,
+		`<!-- hidden --><iframe src="https://evil.example"></iframe>shown`:      `shown`,
+		`<ul><li><em>one</em></li><li><strong>two</strong></li></ul>`:           `<ul><li><em>one</em></li><li><strong>two</strong></li></ul>`,
+		`<p data-x="a > b">3 &lt; 4 and 5 > 4</p>`:                              `<p>3 &lt; 4 and 5 &gt; 4</p>`,
+		`<p>unclosed <img src=x onerror=alert(1)`:                               `<p>unclosed &lt;img src=x onerror=alert(1)`,
+	} {
+		if got := sanitizeHtml(input); got != want {
+			t.Errorf("sanitizeHtml(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestGetShowNotesSanitizesMirroredHtml(t *testing.T) {
+	item := MetaStationItem{
+		Extractor:   PODCAST_EXTRACTOR,
+		Description: `<p>Show notes</p><script>document.cookie</script><p onmouseover="x()">More</p>`,
+	}
+	notes := item.getShowNotes()
+	if strings.Contains(notes.Html, "script") || strings.Contains(notes.Html, "onmouseover") {
+		t.Errorf("HTML = %q", notes.Html)
+	}
+	if strings.Contains(notes.Description, "document.cookie") {
+		t.Errorf("description = %q", notes.Description)
+	}
+	if notes.Subtitle != "Show notes" {
+		t.Errorf("subtitle = %q", notes.Subtitle)
+	}
+}
@@ -0,0 +1,95 @@
+package rss
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const YOUTUBE_EXTRACTOR = "Youtube"
+
+var unsafeIdChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// VideoSource identifies a single video or track on any site yt-dlp supports
+type VideoSource struct {
+	Extractor string `json:"extractor"`
+	ID        string `json:"id"`
+	Url       string `json:"url"`
+}
+
+// getGUID namespaces the ID by extractor. YouTube IDs stay bare so episodes
+// published before other sites were supported keep their GUIDs.
+func (source VideoSource) getGUID() string {
+	if source.isYoutube() {
+		return source.ID
+	}
+	return strings.ToLower(source.Extractor) + "_" + unsafeIdChars.ReplaceAllString(source.ID, "-")
+}
+
+func (source VideoSource) isYoutube() bool {
+	return len(source.Extractor) == 0 || source.Extractor == YOUTUBE_EXTRACTOR
+}
+
+// getTimestampLink points the item's link at the given offset where the site supports it
+func getTimestampLink(item MetaStationItem, seconds int) string {
+	if !(VideoSource{Extractor: item.Extractor}).isYoutube() {
+		return item.Link
+	}
+	u, err := url.Parse(item.Link)
+	if err != nil {
+		return item.Link
+	}
+	// parts of a split video link to where they start, replace that
+	query := u.Query()
+	query.Set("t", fmt.Sprintf("%ds", seconds))
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+func getVideoSource(ctx context.Context, link string) (VideoSource, error) {
+	out, err := ytdlp(
+		ctx,
+		"--quiet",
+		"--skip-download",
+		"--print",
+		"%(extractor_key)s\t%(id)s\t%(webpage_url)s",
+		link,
+	)
+	if err != nil {
+		logError(err, "Get Video Source")
+		return VideoSource{}, err
+	}
+	fields := strings.Split(strings.TrimSpace(out), "\t")
+	if len(fields) != 3 {
+		return VideoSource{}, fmt.Errorf("unexpected yt-dlp output for %s", link)
+	}
+	return VideoSource{
+		Extractor: fields[0],
+		ID:        fields[1],
+		Url:       fields[2],
+	}, nil
+}
+
+// getVideoChannelUrl returns the page of the channel or user that uploaded the video
+func getVideoChannelUrl(ctx context.Context, link string) (string, error) {
+	out, err := ytdlp(
+		ctx,
+		"--quiet",
+		"--skip-download",
+		"--print",
+		"%(channel_url,uploader_url|)s",
+		link,
+	)
+	if err != nil {
+		logError(err, "Get Video Channel Url")
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+func isYoutubeUrl(u *url.URL) bool {
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	return host == "youtube.com" || host == "m.youtube.com" || host == "music.youtube.com" || host == "youtu.be"
+}
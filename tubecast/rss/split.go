@@ -0,0 +1,200 @@
+package rss
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// getEpisodeParts cuts a video of the given duration into parts no longer than limit.
+// Chapter boundaries are preferred; parts that are still too long are cut evenly by time.
+func getEpisodeParts(duration float64, chapters []Chapter, limit float64) []EpisodePart {
+	var grouped []EpisodePart
+	var start, end float64
+	for _, chapter := range chapters {
+		if chapter.EndTime-start > limit && end > start {
+			grouped = append(grouped, EpisodePart{Start: start, End: end})
+			start = end
+		}
+		end = chapter.EndTime
+	}
+	if duration > end {
+		end = duration
+	}
+	grouped = append(grouped, EpisodePart{Start: start, End: end})
+
+	var parts []EpisodePart
+	for _, part := range grouped {
+		length := part.End - part.Start
+		count := int(math.Ceil(length / limit))
+		if count <= 1 {
+			parts = append(parts, part)
+			continue
+		}
+		step := length / float64(count)
+		for i := 0; i < count; i++ {
+			parts = append(parts, EpisodePart{
+				Start: part.Start + float64(i)*step,
+				End:   part.Start + float64(i+1)*step,
+			})
+		}
+	}
+	return parts
+}
+
+// addPartsToStation splits the already downloaded audio of metaStationItem and publishes every part as its own episode.
+// The parts share the thumbnail of metaStationItem. The download is kept until every part is published,
+// so a failed attempt only publishes the missing parts the next time. Episodes are evicted for space when evict is set.
+func (metaStation *MetaStation) addPartsToStation(ctx context.Context, metaStationItem MetaStationItem, duration float64, evict bool) (string, error) {
+	var chapters []Chapter
+	// mirrored episodes have no video to read chapters from, they are cut evenly
+	if metaStationItem.Extractor != PODCAST_EXTRACTOR && len(metaStationItem.Link) > 0 {
+		err := Jobs.do(ctx, METADATA, metaStationItem.Link, func() (err error) {
+			chapters, err = getVideoChapters(ctx, metaStationItem.Link)
+			return err
+		})
+		if err != nil {
+			logError(err, "Add Parts to Station - Chapters")
+		}
+	}
+	fullpath := Megh.getLocalAudioFilepath(metaStationItem.GUID, metaStation.Title)
+
+	pubDate, _ := time.Parse(time.RFC1123, metaStationItem.PubDate)
+
+	parts := getEpisodeParts(duration, chapters, SplitDuration.Seconds())
+	failed := 0
+	for i, part := range parts {
+		partItem := metaStationItem
+		partItem.GUID = getPartGUID(metaStationItem.GUID, i+1)
+		unlock := metaStation.lock()
+		published := metaStation.HasItem(partItem.GUID)
+		unlock()
+		if published {
+			// published by an earlier attempt
+			continue
+		}
+		partItem.PartOf = metaStationItem.GUID
+		partItem.Part = i + 1
+		partItem.PartCount = len(parts)
+		partItem.Title = fmt.Sprintf("%s (Part %d of %d)", metaStationItem.Title, i+1, len(parts))
+		partItem.Description = fmt.Sprintf("Part %d of %d of \"%s\".\n%s", i+1, len(parts), metaStationItem.Title, metaStationItem.Description)
+		partItem.ITunesDuration = formatDuration(part.End - part.Start)
+		partItem.Link = getTimestampLink(metaStationItem, int(part.Start))
+		if !pubDate.IsZero() {
+			// keeps the parts in order in apps that sort by publication date
+			partItem.PubDate = pubDate.Add(time.Duration(i) * time.Second).UTC().Format(PUB_DATE_FORMAT)
+		}
+
+		if enclosure, ok := metaStation.acquireMedia(getMediaKey(partItem.GUID, metaStation.getAudioProfile())); ok {
+			// another show has split the video already
+			partItem.MediaKey = getMediaKey(partItem.GUID, metaStation.getAudioProfile())
+			partItem.Enclosure = enclosure
+			unlock = metaStation.lock()
+			metaStation.addToStation(partItem)
+			unlock()
+			continue
+		}
+		var size uint64
+		err := Jobs.do(ctx, DOWNLOAD, metaStationItem.Link, func() (err error) {
+			size, err = cutAudio(ctx, fullpath, Megh.getLocalAudioFilepath(partItem.GUID, metaStation.Title), partItem.Title, part)
+			return err
+		})
+		if err != nil {
+			logError(err, "Add Parts to Station - Cut Audio")
+			failed++
+			continue
+		}
+		key, enclosure, err := metaStation.storeMedia(ctx, partItem.GUID, size, evict)
+		if err != nil {
+			logError(err, "Add Parts to Station - Upload")
+			failed++
+			continue
+		}
+		partItem.MediaKey = key
+		partItem.Enclosure = enclosure
+		unlock = metaStation.lock()
+		metaStation.addToStation(partItem)
+		unlock()
+	}
+	if failed > 0 {
+		return "", fmt.Errorf("could not publish %d of %d parts of the audio", failed, len(parts))
+	}
+	os.Remove(fullpath)
+	defer metaStation.lock()()
+	return metaStation.updateFeed()
+}
+
+// cutAudio copies a part of src to dest, keeping the tags and artwork of src apart from the title
+func cutAudio(ctx context.Context, src, dest, title string, part EpisodePart) (uint64, error) {
+	_, err := run(
+		ctx,
+		"ffmpeg",
+		"-y",
+		"-loglevel",
+		"error",
+		"-i",
+		src,
+		"-ss",
+		strconv.FormatFloat(part.Start, 'f', 3, 64),
+		"-to",
+		strconv.FormatFloat(part.End, 'f', 3, 64),
+		"-map",
+		"0",
+		"-c",
+		"copy",
+		"-id3v2_version",
+		"4",
+		"-metadata",
+		"title="+title,
+		dest,
+	)
+	if err != nil {
+		return 0, err
+	}
+	info, err := os.Stat(dest)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(info.Size()), nil
+}
+
+func getVideoChapters(ctx context.Context, link string) ([]Chapter, error) {
+	out, err := ytdlp(
+		ctx,
+		"--quiet",
+		"--print",
+		"%(chapters)j",
+		link,
+	)
+	if err != nil {
+		return nil, err
+	}
+	out = strings.TrimSpace(out)
+	if out == "" || out == "null" || out == "NA" {
+		return nil, nil
+	}
+	var chapters []Chapter
+	if err := json.Unmarshal([]byte(out), &chapters); err != nil {
+		return nil, err
+	}
+	return chapters, nil
+}
+
+func getPartGUID(id string, part int) string {
+	return fmt.Sprintf("%s_part%d", id, part)
+}
+
+// formatDuration mirrors yt-dlp's duration_string, e.g. 51:51 or 1:02:03
+func formatDuration(seconds float64) string {
+	total := int(math.Round(seconds))
+	h, m, s := total/3600, (total%3600)/60, total%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
@@ -0,0 +1,70 @@
+package rss
+
+import (
+	"math"
+	"testing"
+)
+
+func TestGetEpisodeParts(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		duration float64
+		chapters []Chapter
+		limit    float64
+		want     []EpisodePart
+	}{
+		{
+			name:     "short enough",
+			duration: 3000,
+			limit:    3600,
+			want:     []EpisodePart{{0, 3000}},
+		},
+		{
+			name:     "even cuts without chapters",
+			duration: 9000,
+			limit:    3600,
+			want:     []EpisodePart{{0, 3000}, {3000, 6000}, {6000, 9000}},
+		},
+		{
+			name:     "chapter boundaries",
+			duration: 7000,
+			chapters: []Chapter{{0, 2000, "a"}, {2000, 3500, "b"}, {3500, 5000, "c"}, {5000, 7000, "d"}},
+			limit:    3600,
+			want:     []EpisodePart{{0, 3500}, {3500, 7000}},
+		},
+		{
+			name:     "overlong chapter is cut evenly",
+			duration: 9000,
+			chapters: []Chapter{{0, 1000, "intro"}, {1000, 9000, "talk"}},
+			limit:    3600,
+			want:     []EpisodePart{{0, 1000}, {1000, 3666.667}, {3666.667, 6333.333}, {6333.333, 9000}},
+		},
+		{
+			name:     "chapters end before the video",
+			duration: 8000,
+			chapters: []Chapter{{0, 3000, "a"}, {3000, 6000, "b"}},
+			limit:    3600,
+			want:     []EpisodePart{{0, 3000}, {3000, 5500}, {5500, 8000}},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got := getEpisodeParts(test.duration, test.chapters, test.limit)
+			if len(got) != len(test.want) {
+				t.Fatalf("got %v, want %v", got, test.want)
+			}
+			for i := range got {
+				if math.Abs(got[i].Start-test.want[i].Start) > 0.001 || math.Abs(got[i].End-test.want[i].End) > 0.001 {
+					t.Errorf("part %d = %v, want %v", i+1, got[i], test.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	for seconds, want := range map[float64]string{0: "0:00", 59.6: "1:00", 3111: "51:51", 3723: "1:02:03"} {
+		if got := formatDuration(seconds); got != want {
+			t.Errorf("formatDuration(%v) = %q, want %q", seconds, got, want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package rss
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -24,8 +25,11 @@ func (user *User) createMetaStation(title string, description string) (MetaStati
 	defer cancel()
 
 	localpath2 := Megh.getLocalCoverFilepath(title)
-	localpath1 := strings.Split(localpath2, ".")[0] + ".webp"
-	ConvertImageToCorrectFormat(localpath1, localpath2)
+	if localpath1, err := findImage(strings.TrimSuffix(localpath2, filepath.Ext(localpath2))); err == nil && localpath1 != localpath2 {
+		if err := ConvertImageToCorrectFormat(localpath1, localpath2); err != nil {
+			logError(err, "Create Meta Station - Cover")
+		}
+	}
 	coverImage, _ := Megh.upload(ctx, "", title, COVER)
 	metaStation := MetaStation{
 		ID:             uuid.New(),
@@ -33,7 +37,7 @@ func (user *User) createMetaStation(title string, description string) (MetaStati
 		Description:    description,
 		ChannelCount:   0,
 		CreatedOn:      time.Now(),
-		Language:       "English",
+		Language:       "en",
 		Copyright:      user.Username,
 		ITunesAuthor:   user.Name,
 		ITunesSubtitle: "",
@@ -42,6 +46,7 @@ func (user *User) createMetaStation(title string, description string) (MetaStati
 			Href: coverImage,
 		},
 		ITunesExplicit: "no",
+		AudioProfile:   DEFAULT_AUDIO_PROFILE,
 		ITunesCategories: []Category{
 			{
 				Text: "Technology",
@@ -51,13 +56,66 @@ func (user *User) createMetaStation(title string, description string) (MetaStati
 			Name:  user.Name,
 			Email: user.EmailId,
 		},
-		SubscribedChannel: NewSet[string](),
+		Subscriptions: []Subscription{},
 	}
 	StationNames.Add(title)
 	metaStation.updateFeed()
 	return metaStation, nil
 }
 
+// getLink returns the website of the show, its feed unless one was set
+func (metaStation *MetaStation) getLink() string {
+	if len(metaStation.Url) > 0 {
+		return metaStation.Url
+	}
+	return Megh.getShareableFeedUrl(metaStation.Title)
+}
+
+// getLastBuildDate returns when episodes were last added or removed, so the feed only changes with them.
+// Shows saved before this was tracked use the newest pubDate, or when they were created.
+func (metaStation *MetaStation) getLastBuildDate(items []MetaStationItem) string {
+	changedOn := metaStation.ItemsChangedOn
+	if changedOn.IsZero() {
+		if latest := getLatestPubDate(items); len(latest) > 0 {
+			return latest
+		}
+		changedOn = metaStation.CreatedOn
+	}
+	return changedOn.UTC().Format(PUB_DATE_FORMAT)
+}
+
+// getTTL returns the minutes podcast apps may cache the feed: the show's own ttl,
+// else the interval of the background sync, since the feed does not change in between
+func (metaStation *MetaStation) getTTL() int {
+	if metaStation.TTL > 0 {
+		return metaStation.TTL
+	}
+	if minutes := int(SyncInterval.Minutes()); minutes > 0 {
+		return minutes
+	}
+	return DEFAULT_TTL
+}
+
+// getLatestPubDate returns the pubDate of the newest of the items, sorted newest first
+func getLatestPubDate(items []MetaStationItem) string {
+	if len(items) == 0 {
+		return ""
+	}
+	return items[0].PubDate
+}
+
+// getOwner returns the owner of the show, completed from OWNER_NAME and OWNER_EMAIL for older shows
+func (metaStation *MetaStation) getOwner() ITunesOwner {
+	owner := metaStation.Owner
+	if len(owner.Name) == 0 {
+		owner.Name = Usr.Name
+	}
+	if len(owner.Email) == 0 {
+		owner.Email = Usr.EmailId
+	}
+	return owner
+}
+
 func getStationItems(metaItems []MetaStationItem) []StationItem {
 	items := make([]StationItem, len(metaItems))
 	for i, metaItem := range metaItems {
@@ -67,23 +125,36 @@ func getStationItems(metaItems []MetaStationItem) []StationItem {
 }
 
 func getStationItem(metaItem MetaStationItem) StationItem {
-	return StationItem{
-		GUID:           metaItem.GUID,
-		Title:          metaItem.Title,
-		Enclosure:      metaItem.Enclosure,
-		ITunesImage:    metaItem.ITunesImage,
-		Description:    metaItem.Description,
-		Link:           metaItem.Link,
-		PubDate:        metaItem.PubDate,
-		ITunesDuration: metaItem.ITunesDuration,
-		ITunesExplicit: metaItem.ITunesExplicit,
-		ITunesAuthor:   metaItem.ITunesAuthor,
-		ITunesSubtitle: metaItem.ITunesSubtitle,
-		ITunesSummary:  metaItem.ITunesSummary,
-		// ITunesEpisode:     metaItem.ITunesEpisode,
-		// ITunesSeason:      metaItem.ITunesSeason,
-		// ITunesEpisodeType: metaItem.ITunesEpisodeType,
+	notes := metaItem.getShowNotes()
+	stationItem := StationItem{
+		GUID:              metaItem.GUID,
+		Title:             metaItem.Title,
+		Enclosure:         metaItem.Enclosure,
+		ITunesImage:       metaItem.ITunesImage,
+		Description:       notes.Description,
+		Link:              metaItem.Link,
+		PubDate:           metaItem.PubDate,
+		ITunesDuration:    metaItem.ITunesDuration,
+		ITunesExplicit:    metaItem.ITunesExplicit,
+		ITunesAuthor:      metaItem.ITunesAuthor,
+		ITunesSubtitle:    notes.Subtitle,
+		ITunesSummary:     metaItem.ITunesSummary,
+		ContentEncoded:    &CData{Text: notes.Html},
+		PodcastSeason:     metaItem.PodcastSeason,
+		PodcastEpisode:    metaItem.PodcastEpisode,
+		PodcastPersons:    metaItem.getPodcastPersons(),
+		ITunesEpisode:     metaItem.ITunesEpisode,
+		ITunesSeason:      metaItem.ITunesSeason,
+		ITunesEpisodeType: metaItem.getITunesEpisodeType(),
+	}
+	// the podcast namespace numbers follow the itunes ones unless set
+	if stationItem.PodcastSeason == nil && metaItem.ITunesSeason > 0 {
+		stationItem.PodcastSeason = &PodcastSeason{Number: metaItem.ITunesSeason}
 	}
+	if stationItem.PodcastEpisode == nil && metaItem.ITunesEpisode > 0 {
+		stationItem.PodcastEpisode = &PodcastEpisode{Number: float64(metaItem.ITunesEpisode)}
+	}
+	return stationItem
 }
 
 // func getMetaStation(title string) (MetaStation, error) {
@@ -94,21 +165,53 @@ func getStationItem(metaItem MetaStationItem) StationItem {
 // }
 
 func (metaStation *MetaStation) addToStation(stationItem MetaStationItem) {
+	metaStation.numberEpisode(&stationItem)
 	metaStation.Items = append(metaStation.Items, stationItem)
+	metaStation.ItemsChangedOn = time.Now()
 	// metaStation.
 	metaStation.updateFeed()
 }
 
-func (metaStation *MetaStation) subscribeToChannel(channelId string) {
-	metaStation.SubscribedChannel.Add(channelId)
+func (metaStation *MetaStation) subscribe(sub Subscription) *Subscription {
+	defer metaStation.lock()()
+	if existing := metaStation.getSubscription(sub.getKey()); existing != nil {
+		// videos rejected under other rules are evaluated again
+		if existing.Filter.hasSameRules(sub.Filter) {
+			sub.Filter.Rejected = existing.Filter.Rejected
+		}
+		*existing = sub
+	} else {
+		metaStation.Subscriptions = append(metaStation.Subscriptions, sub)
+	}
 	metaStation.updateFeed()
+	return metaStation.getSubscription(sub.getKey())
+}
+
+// updateSubscription applies change to sub and saves the station. The station is reloaded first,
+// so change must only touch the subscription. A subscription replaced meanwhile is changed too.
+func (metaStation *MetaStation) updateSubscription(sub *Subscription, change func(*Subscription)) {
+	key := sub.getKey()
+	defer metaStation.lock()()
+	if saved := metaStation.getSubscription(key); saved != nil && saved != sub {
+		change(saved)
+	}
+	change(sub)
+	metaStation.saveMetaStationToLocal()
 }
 
+// HasItem reports whether the station has the episode, or every part of a split video
 func (metaStation *MetaStation) HasItem(id string) bool {
+	parts := 0
 	for _, item := range metaStation.Items {
 		if item.GUID == id {
 			return true
 		}
+		if item.PartOf == id {
+			parts++
+			if parts == item.PartCount {
+				return true
+			}
+		}
 	}
 	return false
 }
@@ -117,9 +220,13 @@ func (metaStation *MetaStation) getAllItems() []EpisodeInfo {
 	var out []EpisodeInfo
 	for _, item := range metaStation.Items {
 		out = append(out, EpisodeInfo{
-			Title:   item.Title,
-			Author:  item.ITunesAuthor,
-			PubDate: item.PubDate,
+			GUID:        item.GUID,
+			Title:       item.Title,
+			Author:      item.ITunesAuthor,
+			PubDate:     item.PubDate,
+			Season:      item.ITunesSeason,
+			Episode:     item.ITunesEpisode,
+			EpisodeType: item.getITunesEpisodeType(),
 		})
 	}
 	return out
@@ -144,6 +251,7 @@ func (metaStation *MetaStation) filter(ids []string) []string {
 	return videoIds
 }
 
+// makeSpace evicts the oldest episodes until size more bytes fit, and reports whether they do
 func (metaStation *MetaStation) makeSpace(ctx context.Context, size uint64) bool {
 	for {
 		usage, err := Megh.getUsage(ctx)
@@ -160,25 +268,30 @@ func (metaStation *MetaStation) makeSpace(ctx context.Context, size uint64) bool
 	}
 }
 
+// removeOldestItem evicts the oldest episode whose audio no other show uses, and reports whether
+// that freed storage. Evicting an episode of shared audio would free nothing.
 func (metaStation *MetaStation) removeOldestItem(ctx context.Context) bool {
-	if len(metaStation.Items) == 0 {
-		return false
-	}
-	oldest := metaStation.Items[0].AddedOn
-	oldestIndex := 0
+	oldestIndex := -1
 	for i, item := range metaStation.Items {
-		if item.AddedOn.Before(oldest) {
-			oldest = item.AddedOn
+		if len(item.MediaKey) > 0 && Media.isShared(item.MediaKey, metaStation.Title) {
+			continue
+		}
+		if oldestIndex == -1 || item.AddedOn.Before(metaStation.Items[oldestIndex].AddedOn) {
 			oldestIndex = i
 		}
 	}
-	id := metaStation.Items[oldestIndex].GUID
-	if err := Megh.deleteEpisode(ctx, id, metaStation.Title); err != nil {
+	if oldestIndex == -1 {
+		return false
+	}
+	freed, err := metaStation.deleteItemFiles(ctx, metaStation.Items[oldestIndex])
+	if err != nil {
 		// fmt.Printf("error-1: %v\n", err)
 		return false
 	}
+	metaStation.exclude(metaStation.Items[oldestIndex], EVICTED_BY_QUOTA)
 	metaStation.Items = append(metaStation.Items[:oldestIndex], metaStation.Items[oldestIndex+1:]...)
+	metaStation.ItemsChangedOn = time.Now()
 	metaStation.updateFeed()
 	// fmt.Printf("file deleted with id %v\n", id)
-	return true
+	return freed
 }
@@ -1,12 +1,12 @@
 package rss
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
 	"fmt"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -35,6 +35,30 @@ func (metaStation *MetaStation) saveMetaStationToLocal() error {
 	return os.Rename(tmp, path)
 }
 
+// refresh reloads the station from disk. While the list of subscriptions is unchanged they are
+// updated in place, so a sync keeps working on the saved subscription. A show not saved yet is left as it is.
+func (metaStation *MetaStation) refresh() {
+	fresh, err := loadMetaStationFromLocal(Megh.getLocalStationFilepath(metaStation.Title))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logError(err, "Refresh Station")
+		}
+		return
+	}
+	subs := metaStation.Subscriptions
+	*metaStation = fresh
+	if len(subs) != len(fresh.Subscriptions) {
+		return
+	}
+	for i := range subs {
+		if subs[i].getKey() != fresh.Subscriptions[i].getKey() {
+			return
+		}
+	}
+	copy(subs, fresh.Subscriptions)
+	metaStation.Subscriptions = subs
+}
+
 // Loads Station Meta data from the local
 func loadMetaStationFromLocal(path string) (MetaStation, error) {
 	f, err := os.Open(path)
@@ -47,39 +71,23 @@ func loadMetaStationFromLocal(path string) (MetaStation, error) {
 	if err := dec.Decode(&metaStation); err != nil {
 		return MetaStation{}, err
 	}
-	// if metaStation.SubscribedChannel == nil {
-	// 	metaStation.SubscribedChannel = NewSet[string]()
-	// 	return metaStation, errors.New("black magic")
-	// }
+	metaStation.migrateSubscriptions()
 	return metaStation, nil
 }
 
 // Atomatically save Station data
 func (station *Station) saveFeed() (string, error) {
-	if err := os.MkdirAll(FEED_BASE, 0o755); err != nil {
-		return "", err
+	if report := station.validate(); report.HasErrors() {
+		if StrictValidation {
+			return "", fmt.Errorf("feed not published, %v", report)
+		}
+		logError(errors.New(report.String()), "Save Feed - Validation")
 	}
-	path := Megh.getLocalFeedFilepath(station.Title)
-	tmp := path + ".tmp"
-
-	f, err := os.Create(tmp)
+	data, err := station.encodeFeed()
 	if err != nil {
 		return "", err
 	}
-
-	defer f.Close()
-	defer os.Remove(tmp)
-
-	enc := xml.NewEncoder(f)
-	enc.Indent("", "  ")
-	f.WriteString(xml.Header)
-	f.WriteString("<rss xmlns:itunes=\"http://www.itunes.com/dtds/podcast-1.0.dtd\" xmlns:podcast=\"https://podcastindex.org/namespace/1.0\" version=\"2.0\">\n  ")
-	// f.WriteString("<rss xmlns:itunes=\"http://www.itunes.com/dtds/podcast-1.0.dtd\" version=\"2.0\">")
-	if err := enc.Encode(station); err != nil {
-		return "", err
-	}
-	f.WriteString("\n</rss>")
-	if err = os.Rename(tmp, path); err != nil {
+	if err := writeFeedFile(Megh.getLocalFeedFilepath(station.Title), data); err != nil {
 		return "", err
 	}
 
@@ -91,6 +99,21 @@ func (station *Station) saveFeed() (string, error) {
 	return Megh.getShareableFeedUrl(station.Title), nil
 }
 
+// encodeFeed renders the RSS document of the station
+func (station *Station) encodeFeed() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString("<rss xmlns:itunes=\"http://www.itunes.com/dtds/podcast-1.0.dtd\" xmlns:podcast=\"https://podcastindex.org/namespace/1.0\" xmlns:atom=\"http://www.w3.org/2005/Atom\" xmlns:fh=\"http://purl.org/syndication/history/1.0\" xmlns:content=\"http://purl.org/rss/1.0/modules/content/\" version=\"2.0\">\n  ")
+	// f.WriteString("<rss xmlns:itunes=\"http://www.itunes.com/dtds/podcast-1.0.dtd\" version=\"2.0\">")
+	enc := xml.NewEncoder(&b)
+	enc.Indent("", "  ")
+	if err := enc.Encode(station); err != nil {
+		return nil, err
+	}
+	b.WriteString("\n</rss>")
+	return b.Bytes(), nil
+}
+
 func loadAllMetaStationNames() error {
 	StationNames = NewSet[string]()
 	entries, err := os.ReadDir(STATION_BASE)
@@ -111,6 +134,14 @@ func loadAllMetaStationNames() error {
 	return nil
 }
 
+// ia runs the Internet Archive command through the network profile
+func ia(ctx context.Context, args ...string) (string, error) {
+	if len(args) > 0 && args[0] == "upload" {
+		args = append([]string{"upload", "--retries", strconv.Itoa(Network.Retries)}, args[1:]...)
+	}
+	return runWithEnv(ctx, Network.getIaEnv(), "./ia", args...)
+}
+
 func (cloud *Cloud) upload(ctx context.Context, id, title string, filetype FileType) (string, error) {
 	var localpath, remotepath string
 	var isLocalDelete bool
@@ -120,12 +151,30 @@ func (cloud *Cloud) upload(ctx context.Context, id, title string, filetype FileT
 		remotepath = cloud.getShareableThumbnailUrl(id, title)
 		isLocalDelete = true
 	case AUDIO:
-		localpath = cloud.getLocalAudioFilepath(id, title)
-		remotepath = cloud.getShareableAudioUrl(id, title)
+		// id is the media key, the object is shared by the shows that have the video
+		localpath = cloud.getLocalMediaFilepath(id)
+		remotepath = cloud.getShareableMediaUrl(id)
 		isLocalDelete = true
 	case FEED:
 		localpath = cloud.getLocalFeedFilepath(title)
 		remotepath = cloud.getShareableFeedUrl(title)
+	case FEED_PAGE:
+		// id is the page number
+		number, err := strconv.Atoi(id)
+		if err != nil {
+			return "", err
+		}
+		localpath = cloud.getLocalFeedPageFilepath(title, number)
+		remotepath = cloud.getShareableFeedPageUrl(title, number)
+	case ATOM_FEED:
+		localpath = cloud.getLocalAtomFeedFilepath(title)
+		remotepath = cloud.getShareableAtomFeedUrl(title)
+	case JSON_FEED:
+		localpath = cloud.getLocalJsonFeedFilepath(title)
+		remotepath = cloud.getShareableJsonFeedUrl(title)
+	case OPML:
+		localpath = cloud.getLocalOpmlFilepath()
+		remotepath = cloud.getShareableOpmlUrl()
 	case COVER:
 		localpath = cloud.getLocalCoverFilepath(title)
 		remotepath = cloud.getShareableCoverUrl(title)
@@ -137,14 +186,16 @@ func (cloud *Cloud) upload(ctx context.Context, id, title string, filetype FileT
 		// fmt.Printf("could not open localpath: %v\n", localpath)
 		return "", err
 	}
-	_, err := run(
-		ctx,
-		"./ia",
-		"upload",
-		"--no-backup",
-		cloud.ArchiveId,
-		localpath,
-	)
+	err := Jobs.do(ctx, UPLOAD, cloud.ArchiveUrlPrefix, func() error {
+		_, err := ia(
+			ctx,
+			"upload",
+			"--no-backup",
+			cloud.ArchiveId,
+			localpath,
+		)
+		return err
+	})
 	if err != nil {
 		return "", err
 	}
@@ -156,9 +207,8 @@ func (cloud *Cloud) upload(ctx context.Context, id, title string, filetype FileT
 }
 
 func (cloud *Cloud) getUsage(ctx context.Context) (Usage, error) {
-	out, err := run(
+	out, err := ia(
 		ctx,
-		"./ia",
 		"metadata",
 		cloud.ArchiveId,
 	)
@@ -203,9 +253,8 @@ func (cloud *Cloud) getUsage(ctx context.Context) (Usage, error) {
 }
 
 func (cloud *Cloud) deleteEpisode(ctx context.Context, id, title string) error {
-	_, err := run(
+	_, err := ia(
 		ctx,
-		"./ia",
 		"delete",
 		cloud.ArchiveId,
 		fmt.Sprintf("--glob=*%s_%s*", title, id),
@@ -226,42 +275,47 @@ func (cloud *Cloud) deleteEpisode(ctx context.Context, id, title string) error {
 	return err
 }
 
-func (cloud *Cloud) deleteShow(title string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-	defer cancel()
-	_, err := run(
+func (cloud *Cloud) deleteThumbnail(ctx context.Context, id, title string) error {
+	_, err := ia(
 		ctx,
-		"./ia",
 		"delete",
 		cloud.ArchiveId,
-		fmt.Sprintf("--glob=*_%s_*", title),
+		fmt.Sprintf("--glob=%s*", strings.TrimSuffix(cloud.getThumbnailFilename(id, title), ".png")),
 	)
-	if err != nil {
-		return err
-	}
-	_, err = run(
-		ctx,
-		"./ia",
-		"delete",
-		cloud.ArchiveId,
-		fmt.Sprintf("--glob=cover_%s*", title),
-	)
-	_, err = run(
+	return err
+}
+
+func (cloud *Cloud) deleteMedia(ctx context.Context, key string) error {
+	_, err := ia(
 		ctx,
-		"./ia",
 		"delete",
 		cloud.ArchiveId,
-		fmt.Sprintf("--glob=*%s.xml", title),
+		cloud.getMediaFilename(key),
 	)
 	return err
 }
 
+func (cloud *Cloud) deleteShow(title string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	var errs []error
+	for _, files := range [][]string{
+		{fmt.Sprintf("--glob=*_%s_*", title)},
+		{fmt.Sprintf("--glob=cover_%s*", title)},
+		{fmt.Sprintf("--glob=*%s.xml", title)},
+		{fmt.Sprintf("--glob=%s_page*.xml", strings.TrimSuffix(cloud.getFeedFilename(title), ".xml"))},
+		{cloud.getFeedFilename(title), cloud.getAtomFeedFilename(title), cloud.getJsonFeedFilename(title)},
+	} {
+		if _, err := ia(ctx, append([]string{"delete", cloud.ArchiveId}, files...)...); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // fetchFinalURL follows redirects and returns the ultimate URL as a string.
 func fetchFinalURL(rawURL string) (string, error) {
-	client := &http.Client{
-		Timeout: 15 * time.Second,
-	}
-	resp, err := client.Get(rawURL)
+	resp, err := Network.getHttpClient(15 * time.Second).Get(rawURL)
 	if err != nil {
 		return "", err
 	}
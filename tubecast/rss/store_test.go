@@ -0,0 +1,46 @@
+package rss
+
+import "testing"
+
+func TestLockReloadsStation(t *testing.T) {
+	base := STATION_BASE
+	STATION_BASE = t.TempDir()
+	t.Cleanup(func() { STATION_BASE = base })
+
+	saved := MetaStation{Title: "Fixture", Subscriptions: []Subscription{{Kind: CHANNEL_VIDEOS, ID: "UCxxxxxxxxxxxxxxxxxxxxxx"}}}
+	if err := saved.saveMetaStationToLocal(); err != nil {
+		t.Fatal(err)
+	}
+	job, err := loadMetaStationFromLocal(Megh.getLocalStationFilepath("Fixture"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub := &job.Subscriptions[0]
+
+	// the TUI saves a change while the job still works on its copy
+	tui, _ := loadMetaStationFromLocal(Megh.getLocalStationFilepath("Fixture"))
+	unlock := tui.lock()
+	tui.Items = append(tui.Items, MetaStationItem{GUID: "added-by-hand"})
+	tui.Subscriptions[0].ETag = `"v2"`
+	tui.saveMetaStationToLocal()
+	unlock()
+
+	unlock = job.lock()
+	job.Items = append(job.Items, MetaStationItem{GUID: "added-by-job"})
+	job.saveMetaStationToLocal()
+	unlock()
+
+	got, _ := loadMetaStationFromLocal(Megh.getLocalStationFilepath("Fixture"))
+	if len(got.Items) != 2 {
+		t.Errorf("saved items = %+v, want both episodes", got.Items)
+	}
+	if sub != &job.Subscriptions[0] || sub.ETag != `"v2"` {
+		t.Errorf("the subscription a sync holds was not updated in place: %+v", *sub)
+	}
+
+	job.updateSubscription(sub, func(sub *Subscription) { sub.LastModified = "yesterday" })
+	got, _ = loadMetaStationFromLocal(Megh.getLocalStationFilepath("Fixture"))
+	if len(got.Items) != 2 || got.Subscriptions[0].ETag != `"v2"` || got.Subscriptions[0].LastModified != "yesterday" {
+		t.Errorf("station after updateSubscription = %+v", got)
+	}
+}
@@ -0,0 +1,243 @@
+package rss
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+type SubscriptionKind string
+
+const (
+	CHANNEL_VIDEOS   SubscriptionKind = "videos"
+	CHANNEL_STREAMS  SubscriptionKind = "streams"
+	CHANNEL_PODCASTS SubscriptionKind = "podcasts"
+	PLAYLIST         SubscriptionKind = "playlist"
+	// channel, user or playlist page on any other site yt-dlp supports
+	SOURCE_URL SubscriptionKind = "url"
+	// RSS feed of another podcast whose episodes are mirrored
+	PODCAST_FEED SubscriptionKind = "rss"
+)
+
+// parseSubscription accepts a handle, a channel ID (UC...), a playlist ID, any
+// YouTube channel, channel tab or playlist URL, or a channel or playlist URL of another site.
+func parseSubscription(input string) (Subscription, error) {
+	input = strings.TrimSpace(input)
+	if len(input) == 0 {
+		return Subscription{}, errors.New("channel or playlist is empty")
+	}
+	if !strings.Contains(input, "/") && !strings.Contains(input, "?") {
+		switch {
+		case isChannelId(input):
+			return Subscription{Kind: CHANNEL_VIDEOS, ID: input}, nil
+		case isPlaylistId(input):
+			return Subscription{Kind: PLAYLIST, ID: input}, nil
+		default:
+			return Subscription{Kind: CHANNEL_VIDEOS, ID: "@" + strings.TrimPrefix(input, "@")}, nil
+		}
+	}
+	if !strings.Contains(input, "://") {
+		input = "https://" + input
+	}
+	u, err := url.Parse(input)
+	if err != nil {
+		return Subscription{}, err
+	}
+	if !isYoutubeUrl(u) {
+		return Subscription{Kind: SOURCE_URL, ID: u.String()}, nil
+	}
+	if list := u.Query().Get("list"); len(list) > 0 {
+		return Subscription{Kind: PLAYLIST, ID: list}, nil
+	}
+	// Atom feed of a channel or playlist, as found in OPML exports
+	if u.Path == "/feeds/videos.xml" {
+		if id := u.Query().Get("channel_id"); isChannelId(id) {
+			return Subscription{Kind: CHANNEL_VIDEOS, ID: id}, nil
+		}
+		if id := u.Query().Get("playlist_id"); len(id) > 0 {
+			return Subscription{Kind: PLAYLIST, ID: id}, nil
+		}
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	var sub Subscription
+	var rest []string
+	switch {
+	case strings.HasPrefix(segments[0], "@"):
+		sub.ID, rest = segments[0], segments[1:]
+	case segments[0] == "channel" && len(segments) > 1:
+		sub.ID, rest = segments[1], segments[2:]
+	case (segments[0] == "c" || segments[0] == "user") && len(segments) > 1:
+		// legacy custom URLs are resolved to the channel ID on subscribe
+		sub.ID, rest = segments[0]+"/"+segments[1], segments[2:]
+	default:
+		return Subscription{}, fmt.Errorf("not a channel or playlist url: %s", input)
+	}
+	sub.Kind = CHANNEL_VIDEOS
+	if len(rest) > 0 {
+		switch SubscriptionKind(rest[0]) {
+		case CHANNEL_STREAMS, CHANNEL_PODCASTS:
+			sub.Kind = SubscriptionKind(rest[0])
+		}
+	}
+	return sub, nil
+}
+
+func isChannelId(id string) bool {
+	return len(id) == 24 && strings.HasPrefix(id, "UC")
+}
+
+func isPlaylistId(id string) bool {
+	for _, prefix := range []string{"PL", "UU", "OL", "FL", "RD"} {
+		if strings.HasPrefix(id, prefix) && len(id) >= 16 {
+			return true
+		}
+	}
+	return false
+}
+
+func (sub *Subscription) getFeedUrl() string {
+	switch sub.Kind {
+	case SOURCE_URL, PODCAST_FEED:
+		return sub.ID
+	case PLAYLIST:
+		return "https://www.youtube.com/playlist?list=" + sub.ID
+	}
+	base := "https://www.youtube.com/" + sub.ID
+	if isChannelId(sub.ID) {
+		base = "https://www.youtube.com/channel/" + sub.ID
+	}
+	return base + "/" + string(sub.Kind)
+}
+
+func (sub *Subscription) getKey() string {
+	return string(sub.Kind) + ":" + sub.ID
+}
+
+// resolve replaces YouTube handles and legacy URLs by the canonical channel ID and fills in the display name
+func (sub *Subscription) resolve(ctx context.Context) error {
+	if sub.Kind == PODCAST_FEED {
+		station, _, err := sub.fetchPodcastFeed(ctx)
+		if err != nil {
+			logError(err, "Resolve Subscription")
+			return err
+		}
+		if len(sub.Name) == 0 && station != nil {
+			sub.Name = station.Title
+		}
+		return nil
+	}
+	if sub.Kind == PLAYLIST || sub.Kind == SOURCE_URL {
+		out, err := ytdlp(
+			ctx,
+			"--quiet",
+			"--flat-playlist",
+			"--playlist-items",
+			"0",
+			"--print",
+			"playlist:%(title)s",
+			sub.getFeedUrl(),
+		)
+		if err != nil {
+			logError(err, "Resolve Subscription")
+			return err
+		}
+		if len(sub.Name) == 0 {
+			sub.Name = strings.TrimSpace(out)
+		}
+		return nil
+	}
+	if isChannelId(sub.ID) && len(sub.Name) > 0 {
+		return nil
+	}
+	out, err := ytdlp(
+		ctx,
+		"--quiet",
+		"--flat-playlist",
+		"--playlist-items",
+		"0",
+		"--print",
+		"playlist:%(channel_id)s\t%(uploader_id)s",
+		sub.getFeedUrl(),
+	)
+	if err != nil {
+		logError(err, "Resolve Subscription")
+		return err
+	}
+	fields := strings.Split(strings.TrimSpace(out), "\t")
+	if len(fields) != 2 || !isChannelId(fields[0]) {
+		return fmt.Errorf("could not resolve channel %s", sub.ID)
+	}
+	if len(sub.Name) == 0 {
+		sub.Name = strings.TrimPrefix(fields[1], "@")
+		if len(sub.Name) == 0 || sub.Name == "NA" {
+			sub.Name = strings.TrimPrefix(sub.ID, "@")
+		}
+	}
+	sub.ID = fields[0]
+	return nil
+}
+
+func (sub *Subscription) getDisplayName() string {
+	if len(sub.Name) > 0 {
+		return sub.Name
+	}
+	return sub.ID
+}
+
+func (sub *Subscription) getAuthor(ctx context.Context, source VideoSource) string {
+	if sub.Kind != PLAYLIST && sub.Kind != SOURCE_URL {
+		return sub.Name
+	}
+	// playlists mix uploads from several channels
+	author, _ := getVideoUsername(ctx, source.Url)
+	return strings.TrimPrefix(author, "@")
+}
+
+func (metaStation *MetaStation) getSubscription(key string) *Subscription {
+	for i := range metaStation.Subscriptions {
+		if metaStation.Subscriptions[i].getKey() == key {
+			return &metaStation.Subscriptions[i]
+		}
+	}
+	return nil
+}
+
+// findSubscription looks up the subscription matching a handle, ID or URL as accepted by parseSubscription
+func (metaStation *MetaStation) findSubscription(ctx context.Context, channel string) (*Subscription, error) {
+	if existing := metaStation.getSubscription(string(PODCAST_FEED) + ":" + strings.TrimSpace(channel)); existing != nil {
+		return existing, nil
+	}
+	sub, err := parseSubscription(channel)
+	if err != nil {
+		return nil, err
+	}
+	if existing := metaStation.getSubscription(sub.getKey()); existing != nil {
+		return existing, nil
+	}
+	if err := sub.resolve(ctx); err != nil {
+		return nil, err
+	}
+	if existing := metaStation.getSubscription(sub.getKey()); existing != nil {
+		return existing, nil
+	}
+	return nil, fmt.Errorf("show `%s` is not subscribed to %s", metaStation.Title, channel)
+}
+
+// migrateSubscriptions turns the channel handles of older station files into typed subscriptions
+func (metaStation *MetaStation) migrateSubscriptions() {
+	if metaStation.SubscribedChannel != nil {
+		for channel := range metaStation.SubscribedChannel.Value {
+			sub := Subscription{
+				Kind: CHANNEL_VIDEOS,
+				ID:   "@" + strings.TrimPrefix(channel, "@"),
+				Name: strings.TrimPrefix(channel, "@"),
+			}
+			if metaStation.getSubscription(sub.getKey()) == nil {
+				metaStation.Subscriptions = append(metaStation.Subscriptions, sub)
+			}
+		}
+	}
+	metaStation.SubscribedChannel = nil
+}
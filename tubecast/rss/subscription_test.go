@@ -0,0 +1,44 @@
+package rss
+
+import "testing"
+
+func TestParseSubscription(t *testing.T) {
+	for _, test := range []struct {
+		input string
+		want  Subscription
+	}{
+		{"@Veritasium", Subscription{Kind: CHANNEL_VIDEOS, ID: "@Veritasium"}},
+		{"veritasium", Subscription{Kind: CHANNEL_VIDEOS, ID: "@veritasium"}},
+		{" UCHnyfMqiRRG1u-2MsSQLbXA ", Subscription{Kind: CHANNEL_VIDEOS, ID: "UCHnyfMqiRRG1u-2MsSQLbXA"}},
+		{"PLFs4vir_WsTwEd-nJgVJCZPNL3HALHHpF", Subscription{Kind: PLAYLIST, ID: "PLFs4vir_WsTwEd-nJgVJCZPNL3HALHHpF"}},
+		{"https://www.youtube.com/@veritasium", Subscription{Kind: CHANNEL_VIDEOS, ID: "@veritasium"}},
+		{"youtube.com/@veritasium/streams", Subscription{Kind: CHANNEL_STREAMS, ID: "@veritasium"}},
+		{"https://m.youtube.com/@veritasium/podcasts", Subscription{Kind: CHANNEL_PODCASTS, ID: "@veritasium"}},
+		{"https://www.youtube.com/@veritasium/shorts", Subscription{Kind: CHANNEL_VIDEOS, ID: "@veritasium"}},
+		{"https://www.youtube.com/channel/UCHnyfMqiRRG1u-2MsSQLbXA/videos", Subscription{Kind: CHANNEL_VIDEOS, ID: "UCHnyfMqiRRG1u-2MsSQLbXA"}},
+		{"https://www.youtube.com/c/veritasium", Subscription{Kind: CHANNEL_VIDEOS, ID: "c/veritasium"}},
+		{"https://www.youtube.com/user/1veritasium", Subscription{Kind: CHANNEL_VIDEOS, ID: "user/1veritasium"}},
+		{"https://www.youtube.com/playlist?list=PLabc", Subscription{Kind: PLAYLIST, ID: "PLabc"}},
+		{"https://www.youtube.com/watch?v=abc&list=PLabc", Subscription{Kind: PLAYLIST, ID: "PLabc"}},
+		{"https://www.youtube.com/feeds/videos.xml?channel_id=UCHnyfMqiRRG1u-2MsSQLbXA", Subscription{Kind: CHANNEL_VIDEOS, ID: "UCHnyfMqiRRG1u-2MsSQLbXA"}},
+		{"https://www.youtube.com/feeds/videos.xml?playlist_id=PLabc", Subscription{Kind: PLAYLIST, ID: "PLabc"}},
+		{"https://vimeo.com/channels/staffpicks", Subscription{Kind: SOURCE_URL, ID: "https://vimeo.com/channels/staffpicks"}},
+	} {
+		got, err := parseSubscription(test.input)
+		if err != nil {
+			t.Errorf("parseSubscription(%q): %v", test.input, err)
+			continue
+		}
+		if got.Kind != test.want.Kind || got.ID != test.want.ID {
+			t.Errorf("parseSubscription(%q) = %s %q, want %s %q", test.input, got.Kind, got.ID, test.want.Kind, test.want.ID)
+		}
+	}
+}
+
+func TestParseSubscriptionErrors(t *testing.T) {
+	for _, input := range []string{"", "   ", "https://www.youtube.com/watch?v=abc", "https://www.youtube.com/"} {
+		if sub, err := parseSubscription(input); err == nil {
+			t.Errorf("parseSubscription(%q) = %+v, want an error", input, sub)
+		}
+	}
+}
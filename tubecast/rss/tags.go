@@ -0,0 +1,54 @@
+package rss
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// tagAudio replaces the tags yt-dlp left in the downloaded MP3 of metaStationItem by ID3v2.4 tags
+// and embeds its thumbnail, or the show cover when it has none, as front cover. It returns the new size of the file.
+// Audio is tagged once, by the show that uploads it, and other shows reusing it keep those tags.
+func (metaStation *MetaStation) tagAudio(ctx context.Context, metaStationItem MetaStationItem) (uint64, error) {
+	path := Megh.getLocalAudioFilepath(metaStationItem.GUID, metaStation.Title)
+	tmp := path + ".tagged.mp3"
+	defer os.Remove(tmp)
+
+	args := []string{"-y", "-loglevel", "error", "-i", path}
+	thumbnail := Megh.getLocalThumbnailFilepath(metaStationItem.GUID, metaStation.Title)
+	if _, err := os.Stat(thumbnail); err != nil {
+		thumbnail = Megh.getLocalCoverFilepath(metaStation.Title)
+	}
+	_, err := os.Stat(thumbnail)
+	hasArtwork := err == nil
+	if hasArtwork {
+		args = append(args, "-i", thumbnail, "-map", "0:a", "-map", "1:v", "-c:v", "mjpeg", "-disposition:v", "attached_pic",
+			"-metadata:s:v", "title=Album cover", "-metadata:s:v", "comment=Cover (front)")
+	} else {
+		args = append(args, "-map", "0:a")
+	}
+	args = append(args,
+		"-c:a", "copy",
+		"-map_metadata", "-1",
+		"-id3v2_version", "4",
+		"-metadata", "title="+metaStationItem.Title,
+		"-metadata", "artist="+metaStationItem.ITunesAuthor,
+		"-metadata", "album="+metaStation.Title,
+		"-metadata", "comment="+metaStationItem.Link,
+	)
+	if pubDate, err := time.Parse(time.RFC1123, metaStationItem.PubDate); err == nil {
+		args = append(args, "-metadata", "date="+pubDate.Format(time.DateOnly))
+	}
+	args = append(args, tmp)
+	if _, err := run(ctx, "ffmpeg", args...); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return 0, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(info.Size()), nil
+}
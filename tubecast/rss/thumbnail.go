@@ -0,0 +1,118 @@
+package rss
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type thumbnail struct {
+	Url    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// getBestThumbnailUrl returns the thumbnail of the video with the most pixels. Entries without
+// a resolution lose against those with one; among equals the later one wins, as yt-dlp sorts them by preference.
+func getBestThumbnailUrl(ctx context.Context, link string) (string, error) {
+	out, err := ytdlp(
+		ctx,
+		"--quiet",
+		"--print",
+		"%(thumbnails)j",
+		link,
+	)
+	if err != nil {
+		return "", err
+	}
+	var thumbnails []thumbnail
+	if err := json.Unmarshal([]byte(strings.TrimSpace(out)), &thumbnails); err != nil {
+		return "", fmt.Errorf("could not read the thumbnails of %s: %w", link, err)
+	}
+	best := -1
+	for i, t := range thumbnails {
+		if len(t.Url) == 0 {
+			continue
+		}
+		if best == -1 || t.Width*t.Height >= thumbnails[best].Width*thumbnails[best].Height {
+			best = i
+		}
+	}
+	if best == -1 {
+		return "", fmt.Errorf("%s has no thumbnail", link)
+	}
+	return thumbnails[best].Url, nil
+}
+
+// getImageExtension detects the format of an image from its first bytes
+func getImageExtension(data []byte) (string, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		return ".jpg", nil
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return ".png", nil
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return ".webp", nil
+	case len(data) >= 12 && string(data[4:8]) == "ftyp" && (string(data[8:12]) == "avif" || string(data[8:12]) == "avis"):
+		return ".avif", nil
+	}
+	return "", errors.New("unknown image format")
+}
+
+// downloadImage saves the image at link next to base, with the extension of its actual format, and returns its path
+func downloadImage(ctx context.Context, link, base string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := Network.getHttpClient(time.Minute).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("thumbnail download returned %s", resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	ext, err := getImageExtension(data)
+	if err != nil {
+		return "", fmt.Errorf("thumbnail %s: %w", link, err)
+	}
+	path := base + ext
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// findImage returns the image saved under base with any of the supported extensions
+func findImage(base string) (string, error) {
+	for _, ext := range []string{".jpg", ".jpeg", ".png", ".webp", ".avif"} {
+		if _, err := os.Stat(base + ext); err == nil {
+			return base + ext, nil
+		}
+	}
+	return "", fmt.Errorf("no image found at %s", filepath.Base(base))
+}
+
+// decodeAvif converts an AVIF image, which the image packages cannot read, to PNG with ffmpeg
+func decodeAvif(inputPath string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	outputPath := strings.TrimSuffix(inputPath, filepath.Ext(inputPath)) + ".avif.png"
+	if _, err := run(ctx, "ffmpeg", "-y", "-loglevel", "error", "-i", inputPath, outputPath); err != nil {
+		return "", err
+	}
+	return outputPath, nil
+}
@@ -0,0 +1,75 @@
+package rss
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type TombstoneReason string
+
+const (
+	REMOVED_MANUALLY TombstoneReason = "manual"
+	EVICTED_BY_QUOTA TombstoneReason = "evicted"
+)
+
+// getTombstoneKey is the GUID of the video an episode was made from, the parts of a split video share it
+func (metaStationItem *MetaStationItem) getTombstoneKey() string {
+	if len(metaStationItem.PartOf) > 0 {
+		return metaStationItem.PartOf
+	}
+	return metaStationItem.GUID
+}
+
+// exclude keeps the video of a removed episode from being added again by Sync
+func (metaStation *MetaStation) exclude(metaStationItem MetaStationItem, reason TombstoneReason) {
+	if metaStation.Excluded == nil {
+		metaStation.Excluded = make(map[string]Tombstone)
+	}
+	key := metaStationItem.getTombstoneKey()
+	if _, ok := metaStation.Excluded[key]; ok {
+		return
+	}
+	title := metaStationItem.Title
+	if metaStationItem.PartCount > 0 {
+		title = strings.TrimSuffix(title, fmt.Sprintf(" (Part %d of %d)", metaStationItem.Part, metaStationItem.PartCount))
+	}
+	metaStation.Excluded[key] = Tombstone{
+		Title:     title,
+		Link:      metaStationItem.Link,
+		Reason:    reason,
+		RemovedOn: time.Now(),
+	}
+}
+
+func (metaStation *MetaStation) isExcluded(guid string) bool {
+	_, ok := metaStation.Excluded[guid]
+	return ok
+}
+
+func (metaStation *MetaStation) unexclude(guid string) error {
+	if !metaStation.isExcluded(guid) {
+		return errors.New("video is not excluded from this show")
+	}
+	delete(metaStation.Excluded, guid)
+	return nil
+}
+
+// getExcludedItems lists the excluded videos, most recently removed first
+func (metaStation *MetaStation) getExcludedItems() []ExcludedInfo {
+	var out []ExcludedInfo
+	for guid, tombstone := range metaStation.Excluded {
+		out = append(out, ExcludedInfo{
+			GUID:      guid,
+			Title:     tombstone.Title,
+			Reason:    tombstone.Reason,
+			RemovedOn: tombstone.RemovedOn,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].RemovedOn.After(out[j].RemovedOn)
+	})
+	return out
+}
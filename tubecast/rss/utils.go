@@ -13,7 +13,9 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/nfnt/resize"
 	"golang.org/x/image/webp"
@@ -29,8 +31,22 @@ var FEED_BASE string = "./docs/feed"
 var AUDIO_BASE string = "./tubecast/audio"
 var COVER_BASE string = "./tubecast/cover"
 var THUMBNAIL_BASE string = "./tubecast/thumbnail"
+var JOB_BASE string = "./tubecast/jobs"
 var MaximumStorage uint64 = 2 * 1024 * 1024 * 1024 // 2GB
+var YOUTUBE_FEED_BASE string = "https://www.youtube.com/feeds/videos.xml"
+var AtomDiscovery bool
+var Network = NetworkProfile{UserAgent: DEFAULT_USER_AGENT, Retries: 10, FragmentRetries: 10}
+var Cookies map[string]string                      // site -> cookies file or browser profile, see parseCookies
+var MaximumDuration time.Duration = 24 * time.Hour // longer videos are rejected
+var SplitDuration time.Duration = 5 * time.Hour    // longer videos are split into parts
+var MaxAttempts int = 5                            // failed videos move to the dead-letter list after this many attempts
+var RetryBackoff time.Duration = time.Hour         // wait before the first retry, doubled after every failure
+var SyncInterval time.Duration                     // all shows are synced this often in the background; 0 syncs on demand only
+var FeedItemLimit int                              // episodes in the main feed, older ones are moved to archive pages; 0 lists all
+var StrictValidation bool                          // feeds with validation errors are not published
 var Megh Cloud
+var Jobs = NewScheduler(4, 2, 2, 0, 0)
+var Media = &MediaStore{Entries: make(map[string]*MediaEntry)}
 var Usr User
 
 type FileType int
@@ -40,8 +56,16 @@ const (
 	AUDIO
 	COVER
 	FEED
+	FEED_PAGE
+	ATOM_FEED
+	JSON_FEED
+	OPML
 )
 
+const PUB_DATE_FORMAT = "Mon, 02 Jan 2006 15:04:05 GMT"
+const GENERATOR = "TubeCast"
+const DEFAULT_TTL = 60 // minutes
+
 const (
 	// Apple Podcasts artwork requirements
 	MIN_SIZE = 1400
@@ -108,7 +132,13 @@ func (s Set[T]) MarshalJSON() ([]byte, error) {
 }
 
 func run(ctx context.Context, cmd string, args ...string) (string, error) {
+	return runWithEnv(ctx, nil, cmd, args...)
+}
+
+// runWithEnv runs cmd with the given environment, the one of TubeCast when env is nil
+func runWithEnv(ctx context.Context, env []string, cmd string, args ...string) (string, error) {
 	c := exec.CommandContext(ctx, cmd, args...)
+	c.Env = env
 	var out, err bytes.Buffer
 	c.Stdout = &out
 	c.Stderr = &err
@@ -153,6 +183,13 @@ func convertImageForPodcast(inputPath, outputPath string, format ImageFormat, qu
 		if err != nil {
 			return fmt.Errorf("failed to decode JPEG image: %w", err)
 		}
+	case ".avif":
+		pngPath, err := decodeAvif(inputPath)
+		if err != nil {
+			return fmt.Errorf("failed to decode AVIF image: %w", err)
+		}
+		defer os.Remove(pngPath)
+		return convertImageForPodcast(pngPath, outputPath, format, quality)
 	default:
 		return fmt.Errorf("unsupported input format: %s", ext)
 	}
@@ -258,7 +295,11 @@ func validatePodcastImage(imagePath string) error {
 	if err != nil {
 		return err
 	}
+	return validateArtworkSize(width, height)
+}
 
+// validateArtworkSize checks the dimensions Apple Podcasts accepts for artwork
+func validateArtworkSize(width, height int) error {
 	if width != height {
 		return fmt.Errorf("image must be square, got %dx%d", width, height)
 	}
@@ -271,20 +312,17 @@ func validatePodcastImage(imagePath string) error {
 	return nil
 }
 
-func ConvertImageToCorrectFormat(src, dest string) {
+func ConvertImageToCorrectFormat(src, dest string) error {
 	// fmt.Printf("src: %v, dest: %v\n", src, dest)
-	err := convertImageForPodcast(src, dest, PNG, 0)
-	if err != nil {
-		// fmt.Printf("Error converting to PNG: %v\n", err)
+	if err := convertImageForPodcast(src, dest, PNG, 0); err != nil {
+		return err
 	}
-	err = validatePodcastImage(dest)
-	if err != nil {
-		// fmt.Printf("Validation failed: %v\n", err)
-	} else {
-		// fmt.Println("Image meets Apple Podcasts requirements!")
-		os.Remove(src)
-		// os.Rename(src, dest)
+	if err := validatePodcastImage(dest); err != nil {
+		return err
 	}
+	// fmt.Println("Image meets Apple Podcasts requirements!")
+	os.Remove(src)
+	return nil
 }
 
 func (user *User) getArchiveIdentifier() string {
@@ -303,6 +341,14 @@ func (cloud *Cloud) getLocalFeedFilepath(title string) string {
 	return filepath.Join(FEED_BASE, cloud.getFeedFilename(title))
 }
 
+func (cloud *Cloud) getLocalAtomFeedFilepath(title string) string {
+	return filepath.Join(FEED_BASE, cloud.getAtomFeedFilename(title))
+}
+
+func (cloud *Cloud) getLocalJsonFeedFilepath(title string) string {
+	return filepath.Join(FEED_BASE, cloud.getJsonFeedFilename(title))
+}
+
 func (cloud *Cloud) getLocalCoverFilepath(title string) string {
 	return filepath.Join(COVER_BASE, cloud.getCoverFilename(title))
 }
@@ -323,6 +369,14 @@ func (cloud *Cloud) getFeedFilename(title string) string {
 	return fmt.Sprintf("%s.xml", strings.ReplaceAll(title, " ", "_"))
 }
 
+func (cloud *Cloud) getAtomFeedFilename(title string) string {
+	return fmt.Sprintf("%s.atom", strings.ReplaceAll(title, " ", "_"))
+}
+
+func (cloud *Cloud) getJsonFeedFilename(title string) string {
+	return fmt.Sprintf("%s.json", strings.ReplaceAll(title, " ", "_"))
+}
+
 func (cloud *Cloud) getCoverFilename(title string) string {
 	return fmt.Sprintf("cover_%s.png", title)
 }
@@ -336,10 +390,23 @@ func (cloud *Cloud) getAudioFilename(id string, title string) string {
 }
 
 func (cloud *Cloud) getShareableFeedUrl(title string) string {
+	return cloud.getShareableFeedFileUrl(cloud.getFeedFilename(title))
+}
+
+func (cloud *Cloud) getShareableAtomFeedUrl(title string) string {
+	return cloud.getShareableFeedFileUrl(cloud.getAtomFeedFilename(title))
+}
+
+func (cloud *Cloud) getShareableJsonFeedUrl(title string) string {
+	return cloud.getShareableFeedFileUrl(cloud.getJsonFeedFilename(title))
+}
+
+// feeds are served by GitHub Pages unless they are hosted on Internet Archive
+func (cloud *Cloud) getShareableFeedFileUrl(filename string) string {
 	if !cloud.IsArchive {
-		return cloud.FeedUrlPrefix + cloud.getFeedFilename(title)
+		return cloud.FeedUrlPrefix + filename
 	}
-	return cloud.ArchiveUrlPrefix + cloud.getFeedFilename(title)
+	return cloud.ArchiveUrlPrefix + filename
 }
 
 func (cloud *Cloud) getShareableCoverUrl(title string) string {
@@ -350,13 +417,61 @@ func (cloud *Cloud) getShareableThumbnailUrl(id, title string) string {
 	return cloud.ArchiveUrlPrefix + cloud.getThumbnailFilename(id, title)
 }
 
-func (cloud *Cloud) getShareableAudioUrl(id, title string) string {
-	return cloud.ArchiveUrlPrefix + cloud.getAudioFilename(id, title)
+func (cloud *Cloud) getLocalMediaFilepath(key string) string {
+	return filepath.Join(AUDIO_BASE, cloud.getMediaFilename(key))
+}
+
+func (cloud *Cloud) getMediaFilename(key string) string {
+	return fmt.Sprintf("media_%s.mp3", key)
+}
+
+func (cloud *Cloud) getShareableMediaUrl(key string) string {
+	return cloud.ArchiveUrlPrefix + cloud.getMediaFilename(key)
+}
+
+// getEnvDuration reads a duration such as "90m" or "5h" from the environment
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		logError(err, "Invalid duration in "+key)
+		return fallback
+	}
+	return d
+}
+
+func getEnvInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		logError(err, "Invalid number in "+key)
+		return fallback
+	}
+	return n
+}
+
+func getEnvFloat(key string, fallback float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		logError(err, "Invalid number in "+key)
+		return fallback
+	}
+	return n
 }
 
 func logError(err error, context string) {
 	f, _ := os.OpenFile("error.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
 	defer f.Close()
-	log.SetOutput(f)
-	log.Println(context, ": ", err)
+	// a logger per call, as syncs log from several goroutines
+	log.New(f, "", log.LstdFlags).Println(context, ": ", err)
 }
@@ -0,0 +1,263 @@
+package rss
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"net/http"
+	"net/mail"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Severity string
+
+const (
+	SEVERITY_WARNING Severity = "warning" // published anyway
+	SEVERITY_ERROR   Severity = "error"   // Apple Podcasts or Podcast Index may reject the feed
+)
+
+// FeedIssue is a problem found in a generated feed, Item is the GUID of the episode or empty for the show
+type FeedIssue struct {
+	Severity Severity
+	Item     string
+	Message  string
+}
+
+type FeedReport struct {
+	Title  string
+	Issues []FeedIssue
+}
+
+func (report *FeedReport) add(severity Severity, item, format string, args ...any) {
+	report.Issues = append(report.Issues, FeedIssue{Severity: severity, Item: item, Message: fmt.Sprintf(format, args...)})
+}
+
+func (report FeedReport) HasErrors() bool {
+	for _, issue := range report.Issues {
+		if issue.Severity == SEVERITY_ERROR {
+			return true
+		}
+	}
+	return false
+}
+
+func (report FeedReport) String() string {
+	if len(report.Issues) == 0 {
+		return fmt.Sprintf("%s: feed is valid", report.Title)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s:", report.Title)
+	for _, issue := range report.Issues {
+		if len(issue.Item) > 0 {
+			fmt.Fprintf(&b, "\n  %s: item %s: %s", issue.Severity, issue.Item, issue.Message)
+		} else {
+			fmt.Fprintf(&b, "\n  %s: %s", issue.Severity, issue.Message)
+		}
+	}
+	return b.String()
+}
+
+// artwork URL -> nil once it was found reachable and of the right size, checked once per run
+var artworkChecks sync.Map
+
+// validate checks the feed against the rules of Apple Podcasts and Podcast Index without network access,
+// it runs before every publish
+func (station *Station) validate() FeedReport {
+	report := FeedReport{Title: station.Title}
+	if len(strings.TrimSpace(station.Description)) == 0 {
+		report.add(SEVERITY_WARNING, "", "the show has no description")
+	}
+	if len(station.Owner.Email) == 0 {
+		report.add(SEVERITY_ERROR, "", "itunes:owner has no email, set OWNER_EMAIL")
+	} else if _, err := mail.ParseAddress(station.Owner.Email); err != nil {
+		report.add(SEVERITY_ERROR, "", "itunes:owner email %q is not valid", station.Owner.Email)
+	}
+	if len(station.ITunesImage.Href) == 0 {
+		report.add(SEVERITY_ERROR, "", "the show has no artwork")
+	}
+	if len(station.ITunesCategories) == 0 {
+		report.add(SEVERITY_ERROR, "", "the show has no itunes:category")
+	}
+	for _, category := range station.ITunesCategories {
+		if err := category.validate(); err != nil {
+			report.add(SEVERITY_ERROR, "", "%v", err)
+		}
+	}
+	if !isLanguageCode(station.Language) {
+		report.add(SEVERITY_ERROR, "", "language %q is not an ISO 639 code such as en or en-us", station.Language)
+	}
+	if station.ITunesExplicit != "yes" && station.ITunesExplicit != "no" && station.ITunesExplicit != "true" && station.ITunesExplicit != "false" {
+		report.add(SEVERITY_WARNING, "", "itunes:explicit should be true or false, got %q", station.ITunesExplicit)
+	}
+	if len(station.PubDate) > 0 {
+		if _, err := parseRFC2822(station.PubDate); err != nil {
+			report.add(SEVERITY_ERROR, "", "pubDate %q is not an RFC 2822 date", station.PubDate)
+		}
+	}
+	guids := make(map[string]bool)
+	for _, item := range station.Items {
+		if len(item.GUID) == 0 {
+			report.add(SEVERITY_ERROR, item.Title, "the episode has no guid")
+		} else if guids[item.GUID] {
+			report.add(SEVERITY_ERROR, item.GUID, "the guid is used by another episode")
+		}
+		guids[item.GUID] = true
+		if len(item.Title) == 0 {
+			report.add(SEVERITY_ERROR, item.GUID, "the episode has no title")
+		}
+		if _, err := parseRFC2822(item.PubDate); err != nil {
+			report.add(SEVERITY_ERROR, item.GUID, "pubDate %q is not an RFC 2822 date", item.PubDate)
+		}
+		if len(item.Enclosure.URL) == 0 {
+			report.add(SEVERITY_ERROR, item.GUID, "the enclosure has no url")
+		}
+		if item.Enclosure.Length == 0 {
+			report.add(SEVERITY_ERROR, item.GUID, "the enclosure length is 0")
+		}
+		if len(item.Enclosure.Type) == 0 {
+			report.add(SEVERITY_ERROR, item.GUID, "the enclosure has no type")
+		}
+		if len(item.ITunesImage.Href) == 0 {
+			report.add(SEVERITY_WARNING, item.GUID, "the episode has no artwork")
+		}
+	}
+	for _, err := range station.getPodcastErrors() {
+		report.add(SEVERITY_ERROR, "", "%v", err)
+	}
+	return report
+}
+
+// validateRemote also downloads the artwork of the show, it runs when a show is validated by hand
+func (station *Station) validateRemote(ctx context.Context) FeedReport {
+	report := station.validate()
+	if len(station.ITunesImage.Href) > 0 {
+		if err := checkArtwork(ctx, station.ITunesImage.Href); err != nil {
+			report.add(SEVERITY_ERROR, "", "artwork %s: %v", station.ITunesImage.Href, err)
+		}
+	}
+	return report
+}
+
+// checkArtwork downloads the artwork and checks its format and dimensions
+func checkArtwork(ctx context.Context, link string) error {
+	if _, ok := artworkChecks.Load(link); ok {
+		return nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := Network.getHttpClient(30 * time.Second).Do(req)
+	if err != nil {
+		return fmt.Errorf("not reachable: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("not reachable: %s", resp.Status)
+	}
+	config, format, err := image.DecodeConfig(resp.Body)
+	if err != nil {
+		return err
+	}
+	if format != "jpeg" && format != "png" {
+		return fmt.Errorf("must be a JPEG or PNG, got %s", format)
+	}
+	if err := validateArtworkSize(config.Width, config.Height); err != nil {
+		return err
+	}
+	artworkChecks.Store(link, nil)
+	return nil
+}
+
+// parseRFC2822 accepts the dates podcast apps understand, with a weekday and a numeric or GMT zone
+func parseRFC2822(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC1123Z, time.RFC1123, "Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("invalid RFC 2822 date")
+}
+
+func (category *Category) validate() error {
+	subcategories, ok := ITUNES_CATEGORIES[category.Text]
+	if !ok {
+		return fmt.Errorf("itunes:category %q is not an Apple Podcasts category", category.Text)
+	}
+	if category.Subcategory != nil && !slices.Contains(subcategories, category.Subcategory.Text) {
+		return fmt.Errorf("itunes:category %q is not a subcategory of %q", category.Subcategory.Text, category.Text)
+	}
+	return nil
+}
+
+// isLanguageCode accepts an ISO 639-1 code with an optional region, such as en or en-us
+func isLanguageCode(language string) bool {
+	code, region, found := strings.Cut(strings.ToLower(language), "-")
+	if !slices.Contains(ISO_639_1, code) {
+		return false
+	}
+	return !found || (len(region) >= 2 && len(region) <= 3)
+}
+
+// getLanguageCode turns the language names older shows were created with into ISO 639-1 codes
+func getLanguageCode(language string) string {
+	if code, ok := LANGUAGE_CODES[strings.ToLower(strings.TrimSpace(language))]; ok {
+		return code
+	}
+	return language
+}
+
+// Apple Podcasts categories and their subcategories
+var ITUNES_CATEGORIES = map[string][]string{
+	"Arts":                    {"Books", "Design", "Fashion & Beauty", "Food", "Performing Arts", "Visual Arts"},
+	"Business":                {"Careers", "Entrepreneurship", "Investing", "Management", "Marketing", "Non-Profit"},
+	"Comedy":                  {"Comedy Interviews", "Improv", "Stand-Up"},
+	"Education":               {"Courses", "How To", "Language Learning", "Self-Improvement"},
+	"Fiction":                 {"Comedy Fiction", "Drama", "Science Fiction"},
+	"Government":              {},
+	"History":                 {},
+	"Health & Fitness":        {"Alternative Health", "Fitness", "Medicine", "Mental Health", "Nutrition", "Sexuality"},
+	"Kids & Family":           {"Education for Kids", "Parenting", "Pets & Animals", "Stories for Kids"},
+	"Leisure":                 {"Animation & Manga", "Automotive", "Aviation", "Crafts", "Games", "Hobbies", "Home & Garden", "Video Games"},
+	"Music":                   {"Music Commentary", "Music History", "Music Interviews"},
+	"News":                    {"Business News", "Daily News", "Entertainment News", "News Commentary", "Politics", "Sports News", "Tech News"},
+	"Religion & Spirituality": {"Buddhism", "Christianity", "Hinduism", "Islam", "Judaism", "Religion", "Spirituality"},
+	"Science":                 {"Astronomy", "Chemistry", "Earth Sciences", "Life Sciences", "Mathematics", "Natural Sciences", "Nature", "Physics", "Social Sciences"},
+	"Society & Culture":       {"Documentary", "Personal Journals", "Philosophy", "Places & Travel", "Relationships"},
+	"Sports":                  {"Baseball", "Basketball", "Cricket", "Fantasy Sports", "Football", "Golf", "Hockey", "Rugby", "Running", "Soccer", "Swimming", "Tennis", "Volleyball", "Wilderness", "Wrestling"},
+	"Technology":              {},
+	"True Crime":              {},
+	"TV & Film":               {"After Shows", "Film History", "Film Interviews", "Film Reviews", "TV Reviews"},
+}
+
+var ISO_639_1 = strings.Fields(`
+	aa ab ae af ak am an ar as av ay az ba be bg bh bi bm bn bo br bs ca ce ch co cr cs cu cv cy
+	da de dv dz ee el en eo es et eu fa ff fi fj fo fr fy ga gd gl gn gu gv ha he hi ho hr ht hu
+	hy hz ia id ie ig ii ik io is it iu ja jv ka kg ki kj kk kl km kn ko kr ks ku kv kw ky la lb
+	lg li ln lo lt lu lv mg mh mi mk ml mn mr ms mt my na nb nd ne ng nl nn no nr nv ny oc oj om
+	or os pa pi pl ps pt qu rm rn ro ru rw sa sc sd se sg si sk sl sm sn so sq sr ss st su sv sw
+	ta te tg th ti tk tl tn to tr ts tt tw ty ug uk ur uz ve vi vo wa wo xh yi yo za zh zu`)
+
+var LANGUAGE_CODES = map[string]string{
+	"english":    "en",
+	"spanish":    "es",
+	"french":     "fr",
+	"german":     "de",
+	"italian":    "it",
+	"portuguese": "pt",
+	"dutch":      "nl",
+	"russian":    "ru",
+	"turkish":    "tr",
+	"arabic":     "ar",
+	"hindi":      "hi",
+	"bengali":    "bn",
+	"urdu":       "ur",
+	"indonesian": "id",
+	"japanese":   "ja",
+	"korean":     "ko",
+	"chinese":    "zh",
+}
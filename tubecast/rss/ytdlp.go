@@ -0,0 +1,95 @@
+package rss
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+)
+
+// cookies source that reads the cookies of a browser profile instead of a file,
+// e.g. browser:firefox or browser:chrome:Profile 1
+const COOKIES_BROWSER_PREFIX = "browser:"
+
+// parseCookies reads the COOKIES setting: `site=source` pairs separated by `;`,
+// where source is a cookies.txt file or browser:<browser>[:<profile>].
+// A source without a site applies to every site without its own entry.
+func parseCookies(value string) map[string]string {
+	cookies := make(map[string]string)
+	for _, entry := range strings.Split(value, ";") {
+		entry = strings.TrimSpace(entry)
+		if len(entry) == 0 {
+			continue
+		}
+		site, source, found := strings.Cut(entry, "=")
+		if !found {
+			site, source = "*", entry
+		}
+		site = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(site)), "www.")
+		source = strings.TrimSpace(source)
+		if !strings.HasPrefix(source, COOKIES_BROWSER_PREFIX) {
+			if _, err := os.Stat(source); err != nil {
+				// the error names the site only, never the file
+				logError(errors.New("cookies file for "+site+" is not readable"), "Parse Cookies")
+				continue
+			}
+		}
+		cookies[site] = source
+	}
+	return cookies
+}
+
+// getCookiesArgs returns the yt-dlp options that pass the credentials configured for the site of link
+func getCookiesArgs(link string) []string {
+	source, ok := getCookiesSource(link)
+	if !ok {
+		return nil
+	}
+	if browser, found := strings.CutPrefix(source, COOKIES_BROWSER_PREFIX); found {
+		return []string{"--cookies-from-browser", browser}
+	}
+	return []string{"--cookies", source}
+}
+
+func getCookiesSource(link string) (string, bool) {
+	if u, err := url.Parse(link); err == nil && len(u.Hostname()) > 0 {
+		host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+		if host == "youtu.be" {
+			host = "youtube.com"
+		}
+		// music.youtube.com uses the entry of youtube.com
+		for ; len(host) > 0; host = cutSubdomain(host) {
+			if source, ok := Cookies[host]; ok {
+				return source, true
+			}
+		}
+	}
+	source, ok := Cookies["*"]
+	return source, ok
+}
+
+func cutSubdomain(host string) string {
+	_, parent, found := strings.Cut(host, ".")
+	if !found || !strings.Contains(parent, ".") {
+		return ""
+	}
+	return parent
+}
+
+// ytdlp runs yt-dlp with the given arguments, the last of which is the url.
+// Every yt-dlp call goes through here so the configured credentials and network profile are always passed.
+func ytdlp(ctx context.Context, args ...string) (string, error) {
+	var link string
+	if len(args) > 0 {
+		link = args[len(args)-1]
+	}
+	cookiesArgs := getCookiesArgs(link)
+	out, err := run(ctx, "yt-dlp", slices.Concat(cookiesArgs, Network.getYtdlpArgs(), args)...)
+	if err != nil && len(cookiesArgs) > 0 {
+		// keep the cookies location out of error.log
+		err = errors.New(strings.ReplaceAll(err.Error(), cookiesArgs[1], "<cookies>"))
+	}
+	return out, err
+}